@@ -4,3 +4,9 @@ import _ "embed"
 
 //go:embed tilemap_v2.png
 var TILEMAP []byte
+
+//go:embed ground.png
+var GROUND []byte
+
+//go:embed animations.json
+var ANIMATIONS []byte
@@ -0,0 +1,49 @@
+package collisions
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type ColliderKind int
+
+const (
+	SolidCollider ColliderKind = iota
+	OneWayCollider
+	SlopeCollider
+)
+
+// Collider points to the hitbox it was built from, so it follows the prop owning it.
+// Slope colliders also keep the height of their surface at the left and right edges,
+// measured from the bottom of the hitbox.
+type Collider struct {
+	Rect        *rl.Rectangle
+	Kind        ColliderKind
+	LeftHeight  float32
+	RightHeight float32
+}
+
+func NewCollider(rect *rl.Rectangle, kind ColliderKind) *Collider {
+	return &Collider{
+		Rect: rect,
+		Kind: kind,
+	}
+}
+
+func NewSlopeCollider(rect *rl.Rectangle, leftHeight float32, rightHeight float32) *Collider {
+	return &Collider{
+		Rect:        rect,
+		Kind:        SlopeCollider,
+		LeftHeight:  leftHeight,
+		RightHeight: rightHeight,
+	}
+}
+
+// BlocksFromAbove tells if a rectangle whose bottom was at previousBottom is landing on
+// the collider instead of going through it. Solid colliders always block.
+func (c *Collider) BlocksFromAbove(previousBottom float32) bool {
+	if c.Kind != OneWayCollider {
+		return true
+	}
+
+	return previousBottom <= c.Rect.Y+CONTACT_TOLERANCE
+}
@@ -6,6 +6,10 @@ import (
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// Contacts closer than this (in pixels) are still reported as hits, it absorbs the
+// floating point drift left behind after resolving a movement exactly up to a face.
+const CONTACT_TOLERANCE float32 = 0.01
+
 type Ray2D struct {
 	Origin    rl.Vector2
 	Direction rl.Vector2
@@ -22,49 +26,90 @@ const (
 )
 
 func CheckRectanglesCollision(movingRec rl.Rectangle, staticRec rl.Rectangle) (bool, HitFace) {
-	hits := false
-	hitFace := NoFace
+	manifold, hits := GetCollisionManifold(movingRec, staticRec)
 
-	if movingRec.X < (staticRec.X+staticRec.Width) && (movingRec.X+movingRec.Width) > staticRec.X &&
-		movingRec.Y < (staticRec.Y+staticRec.Height) && (movingRec.Y+movingRec.Height) > staticRec.Y {
-		hits = true
-	}
+	return hits, manifold.Face
+}
 
-	if !hits {
-		return false, hitFace
+// CheckRay2DRectangleCollision casts the ray against the target rectangle expanded by the
+// dimensions of the moving rectangle, so the ray origin must be the center of the moving
+// rectangle and its direction the displacement for the current frame. It returns the time
+// of impact as a fraction of that displacement, only impacts with a time lower than 1
+// happen during the current frame.
+func CheckRay2DRectangleCollision(ray Ray2D, targetRect rl.Rectangle, movingRecDimensions rl.Vector2) (float32, bool, HitFace) {
+	if ray.Direction.X == 0 && ray.Direction.Y == 0 {
+		return 0, false, NoFace
 	}
 
-	topOverlap := math.Abs(float64(movingRec.Y + movingRec.Height - staticRec.Y))
-	bottomOverlap := math.Abs(float64(staticRec.Y + staticRec.Height - movingRec.Y))
-	leftOverlap := math.Abs(float64(movingRec.X + movingRec.Width - staticRec.X))
-	rightOverlap := math.Abs(float64(staticRec.X + staticRec.Width - movingRec.X))
+	expandedRect := rl.NewRectangle(
+		targetRect.X-movingRecDimensions.X/2,
+		targetRect.Y-movingRecDimensions.Y/2,
+		targetRect.Width+movingRecDimensions.X,
+		targetRect.Height+movingRecDimensions.Y,
+	)
 
-	minOverlap := topOverlap
-	hitFace = FaceTop
+	nearX, farX, crossesX := getSlabEntryAndExit(ray.Origin.X, ray.Direction.X, expandedRect.X, expandedRect.X+expandedRect.Width)
+	nearY, farY, crossesY := getSlabEntryAndExit(ray.Origin.Y, ray.Direction.Y, expandedRect.Y, expandedRect.Y+expandedRect.Height)
 
-	if bottomOverlap < minOverlap {
-		minOverlap = bottomOverlap
-		hitFace = FaceBottom
+	if !crossesX || !crossesY {
+		return 0, false, NoFace
 	}
 
-	if leftOverlap < minOverlap {
-		minOverlap = leftOverlap
-		hitFace = FaceLeft
+	timeToHit := max(nearX, nearY)
+	timeToExit := min(farX, farY)
+
+	if timeToHit > timeToExit || timeToExit <= 0 {
+		return 0, false, NoFace
 	}
 
-	if rightOverlap < minOverlap {
-		hitFace = FaceRight
+	var hitFace HitFace
+	var penetration float32
+
+	if nearX > nearY {
+		hitFace = FaceLeft
+		if ray.Direction.X < 0 {
+			hitFace = FaceRight
+		}
+
+		penetration = -timeToHit * float32(math.Abs(float64(ray.Direction.X)))
+	} else {
+		hitFace = FaceTop
+		if ray.Direction.Y < 0 {
+			hitFace = FaceBottom
+		}
+
+		penetration = -timeToHit * float32(math.Abs(float64(ray.Direction.Y)))
 	}
 
-	// This is my poor man's solution to ghost colliding
-	// https://briansemrau.github.io/dealing-with-ghost-collisions/
-	if (hitFace == FaceLeft || hitFace == FaceRight) && bottomOverlap > 0 && bottomOverlap > topOverlap {
-		return false, NoFace
+	// the origin already is inside the expanded rectangle, only resting contacts count
+	if timeToHit < 0 {
+		if penetration > CONTACT_TOLERANCE {
+			return 0, false, NoFace
+		}
+
+		timeToHit = 0
 	}
 
-	return hits, hitFace
+	return timeToHit, true, hitFace
 }
 
-func CheckRay2DRectangleCollision(ray Ray2D, targetRect rl.Rectangle, movingRecDimensions rl.Vector2) (float32, bool, HitFace) {
-	return 0, true, NoFace
+func getSlabEntryAndExit(origin float32, direction float32, slabStart float32, slabEnd float32) (float32, float32, bool) {
+	if direction == 0 {
+		// a ray sliding exactly along a face must not hit it, otherwise the seams between
+		// tiles would stop the player
+		if origin <= slabStart+CONTACT_TOLERANCE || origin >= slabEnd-CONTACT_TOLERANCE {
+			return 0, 0, false
+		}
+
+		return float32(math.Inf(-1)), float32(math.Inf(1)), true
+	}
+
+	entry := (slabStart - origin) / direction
+	exit := (slabEnd - origin) / direction
+
+	if entry > exit {
+		entry, exit = exit, entry
+	}
+
+	return entry, exit, true
 }
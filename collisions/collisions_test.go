@@ -0,0 +1,49 @@
+package collisions
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestRay2DRectangleCollision(t *testing.T) {
+	floor := rl.NewRectangle(0, 0, 16, 8)
+	dimensions := rl.NewVector2(8, 8)
+
+	for _, testCase := range []struct {
+		name      string
+		ray       Ray2D
+		hits      bool
+		timeToHit float32
+		face      HitFace
+	}{
+		// the floor expanded by half the moving box spans -4..20 and -4..12
+		{"falling onto the top", Ray2D{rl.NewVector2(8, -10), rl.NewVector2(0, 20)}, true, 0.3, FaceTop},
+		{"jumping into the bottom", Ray2D{rl.NewVector2(8, 22), rl.NewVector2(0, -20)}, true, 0.5, FaceBottom},
+		{"running into the left side", Ray2D{rl.NewVector2(-10, 4), rl.NewVector2(12, 0)}, true, 0.5, FaceLeft},
+		{"running into the right side", Ray2D{rl.NewVector2(30, 4), rl.NewVector2(-20, 0)}, true, 0.5, FaceRight},
+		{"diagonal onto the top", Ray2D{rl.NewVector2(0, -8), rl.NewVector2(4, 8)}, true, 0.5, FaceTop},
+		{"passing beside", Ray2D{rl.NewVector2(-10, -20), rl.NewVector2(40, 0)}, false, 0, NoFace},
+		{"moving away", Ray2D{rl.NewVector2(8, -10), rl.NewVector2(0, -20)}, false, 0, NoFace},
+		{"sliding along the top", Ray2D{rl.NewVector2(-10, -4), rl.NewVector2(40, 0)}, false, 0, NoFace},
+		{"standing still", Ray2D{rl.NewVector2(8, -4), rl.NewVector2(0, 0)}, false, 0, NoFace},
+		{"resting on the top", Ray2D{rl.NewVector2(8, -4), rl.NewVector2(0, 5)}, true, 0, FaceTop},
+	} {
+		timeToHit, hits, face := CheckRay2DRectangleCollision(testCase.ray, floor, dimensions)
+
+		if hits != testCase.hits || face != testCase.face || timeToHit != testCase.timeToHit {
+			t.Errorf("%s: expected %t on face %d at %f, got %t on face %d at %f", testCase.name, testCase.hits, testCase.face, testCase.timeToHit, hits, face, timeToHit)
+		}
+	}
+}
+
+// impacts further away than the displacement happen on a later frame
+func TestRay2DRectangleCollisionBeyondTheFrame(t *testing.T) {
+	floor := rl.NewRectangle(0, 0, 16, 8)
+	ray := Ray2D{rl.NewVector2(8, -24), rl.NewVector2(0, 10)}
+
+	timeToHit, hits, face := CheckRay2DRectangleCollision(ray, floor, rl.NewVector2(8, 8))
+	if !hits || face != FaceTop || timeToHit < 1 {
+		t.Fatalf("expected a hit on the top after the frame, got %t on face %d at %f", hits, face, timeToHit)
+	}
+}
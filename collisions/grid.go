@@ -0,0 +1,110 @@
+package collisions
+
+import (
+	"math"
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const GRID_CELL_SIZE float32 = 32
+
+type GridCell struct {
+	X int32
+	Y int32
+}
+
+// SpatialGrid is a uniform grid that hashes every collider into all the cells it
+// overlaps, so looking for the colliders around an area only walks a few cells.
+type SpatialGrid struct {
+	CellSize float32
+	Cells    map[GridCell][]*Collider
+}
+
+func NewSpatialGrid(cellSize float32) *SpatialGrid {
+	return &SpatialGrid{
+		CellSize: cellSize,
+		Cells:    make(map[GridCell][]*Collider),
+	}
+}
+
+func (g *SpatialGrid) Insert(collider *Collider) {
+	minCell, maxCell := g.GetCellRange(*collider.Rect)
+
+	for x := minCell.X; x <= maxCell.X; x++ {
+		for y := minCell.Y; y <= maxCell.Y; y++ {
+			cell := GridCell{X: x, Y: y}
+			g.Cells[cell] = append(g.Cells[cell], collider)
+		}
+	}
+}
+
+// Remove expects the collider to be where it was when inserted.
+func (g *SpatialGrid) Remove(collider *Collider) {
+	minCell, maxCell := g.GetCellRange(*collider.Rect)
+
+	for x := minCell.X; x <= maxCell.X; x++ {
+		for y := minCell.Y; y <= maxCell.Y; y++ {
+			cell := GridCell{X: x, Y: y}
+			g.Cells[cell] = slices.DeleteFunc(g.Cells[cell], func(candidate *Collider) bool {
+				return candidate == collider
+			})
+
+			if len(g.Cells[cell]) == 0 {
+				delete(g.Cells, cell)
+			}
+		}
+	}
+}
+
+func (g *SpatialGrid) Clear() {
+	clear(g.Cells)
+}
+
+// Query returns every collider overlapping the area, colliders touching its edges included.
+// A collider spanning several cells is only taken from the first of them inside the area,
+// which like Remove expects it to be where it was when inserted.
+func (g *SpatialGrid) Query(area rl.Rectangle) []*Collider {
+	var found []*Collider
+	minCell, maxCell := g.GetCellRange(area)
+
+	for x := minCell.X; x <= maxCell.X; x++ {
+		for y := minCell.Y; y <= maxCell.Y; y++ {
+			for _, collider := range g.Cells[GridCell{X: x, Y: y}] {
+				if !g.isFirstCell(collider, x, y, minCell) || !isTouching(area, *collider.Rect) {
+					continue
+				}
+
+				found = append(found, collider)
+			}
+		}
+	}
+
+	return found
+}
+
+// isFirstCell tells whether the cell is the first one holding the collider that a query
+// starting at minCell walks over, cells are walked column by column.
+func (g *SpatialGrid) isFirstCell(collider *Collider, x int32, y int32, minCell GridCell) bool {
+	colliderMinCell, _ := g.GetCellRange(*collider.Rect)
+
+	return x == max(colliderMinCell.X, minCell.X) && y == max(colliderMinCell.Y, minCell.Y)
+}
+
+func (g *SpatialGrid) GetCellRange(rect rl.Rectangle) (GridCell, GridCell) {
+	minCell := GridCell{
+		X: int32(math.Floor(float64(rect.X / g.CellSize))),
+		Y: int32(math.Floor(float64(rect.Y / g.CellSize))),
+	}
+	maxCell := GridCell{
+		X: int32(math.Floor(float64((rect.X + rect.Width) / g.CellSize))),
+		Y: int32(math.Floor(float64((rect.Y + rect.Height) / g.CellSize))),
+	}
+
+	return minCell, maxCell
+}
+
+func isTouching(a rl.Rectangle, b rl.Rectangle) bool {
+	return a.X <= b.X+b.Width && a.X+a.Width >= b.X &&
+		a.Y <= b.Y+b.Height && a.Y+a.Height >= b.Y
+}
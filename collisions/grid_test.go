@@ -0,0 +1,112 @@
+package collisions
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// buildLargeRoom lays out a room 50 screens big with a floor on every other row of tiles.
+func buildLargeRoom() []*Collider {
+	var tiles []*Collider
+
+	for x := range 2000 {
+		for y := range 230 {
+			if y%2 != 0 {
+				continue
+			}
+
+			tile := rl.NewRectangle(float32(x*8), float32(y*8), 8, 8)
+			tiles = append(tiles, NewCollider(&tile, SolidCollider))
+		}
+	}
+
+	return tiles
+}
+
+func TestSpatialGridQuery(t *testing.T) {
+	grid := NewSpatialGrid(GRID_CELL_SIZE)
+	tiles := buildLargeRoom()
+	for _, tile := range tiles {
+		grid.Insert(tile)
+	}
+
+	area := rl.NewRectangle(100, 100, 30, 20)
+
+	var expected int
+	for _, tile := range tiles {
+		if isTouching(area, *tile.Rect) {
+			expected++
+		}
+	}
+
+	found := grid.Query(area)
+	if len(found) != expected {
+		t.Fatalf("expected %d rectangles, found %d", expected, len(found))
+	}
+
+	grid.Remove(found[0])
+	if len(grid.Query(area)) != expected-1 {
+		t.Fatalf("removed rectangle is still being returned")
+	}
+}
+
+func TestSpatialGridQueryReturnsWideCollidersOnce(t *testing.T) {
+	grid := NewSpatialGrid(GRID_CELL_SIZE)
+	floor := rl.NewRectangle(-100, 40, 400, 80)
+	wall := rl.NewRectangle(-40, -200, 8, 600)
+	grid.Insert(NewCollider(&floor, SolidCollider))
+	grid.Insert(NewCollider(&wall, SolidCollider))
+
+	for _, area := range []rl.Rectangle{
+		rl.NewRectangle(-200, -300, 600, 800), // covers both
+		rl.NewRectangle(-50, 50, 100, 100),    // starts inside both
+		rl.NewRectangle(-44, 100, 4, 4),       // a single cell
+	} {
+		if found := grid.Query(area); len(found) != 2 {
+			t.Errorf("expected both colliders once querying %v, found %d", area, len(found))
+		}
+	}
+}
+
+func BenchmarkLinearCollisions(b *testing.B) {
+	tiles := buildLargeRoom()
+	player := rl.NewRectangle(8000, 900, 8, 8)
+
+	for b.Loop() {
+		for _, tile := range tiles {
+			CheckRectanglesCollision(player, *tile.Rect)
+		}
+	}
+}
+
+func BenchmarkSpatialGridCollisions(b *testing.B) {
+	grid := NewSpatialGrid(GRID_CELL_SIZE)
+	for _, tile := range buildLargeRoom() {
+		grid.Insert(tile)
+	}
+
+	player := rl.NewRectangle(8000, 900, 8, 8)
+
+	for b.Loop() {
+		for _, tile := range grid.Query(player) {
+			CheckRectanglesCollision(player, *tile.Rect)
+		}
+	}
+}
+
+// BenchmarkSpatialGridWideQuery looks up a whole screen of merged floors, every one of them
+// spanning many cells.
+func BenchmarkSpatialGridWideQuery(b *testing.B) {
+	grid := NewSpatialGrid(GRID_CELL_SIZE)
+	for y := range 90 {
+		floor := rl.NewRectangle(0, float32(y*16), 16000, 8)
+		grid.Insert(NewCollider(&floor, SolidCollider))
+	}
+
+	screen := rl.NewRectangle(8000, 0, 320, 1440)
+
+	for b.Loop() {
+		grid.Query(screen)
+	}
+}
@@ -0,0 +1,195 @@
+package collisions
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type Axis int
+
+const (
+	AxisX Axis = iota
+	AxisY
+)
+
+// Manifold describes how two overlapping rectangles touch. The normal points away from
+// the static rectangle and, together with the penetration depth, is the translation that
+// separates the moving one.
+type Manifold struct {
+	Normal      rl.Vector2
+	Penetration float32
+	ContactRect rl.Rectangle
+	Face        HitFace
+}
+
+// Contacts tells which sides of a rectangle are resting against something solid.
+// OnOneWay is set when all the ground below is made of one way colliders.
+type Contacts struct {
+	Ground    bool
+	OnOneWay  bool
+	OnSlope   bool
+	Ceiling   bool
+	LeftWall  bool
+	RightWall bool
+}
+
+// GetCollisionManifold separates the rectangles through the face with the smallest overlap.
+func GetCollisionManifold(movingRec rl.Rectangle, staticRec rl.Rectangle) (Manifold, bool) {
+	if !isOverlapping(movingRec, staticRec) {
+		return Manifold{Face: NoFace}, false
+	}
+
+	candidates := []Manifold{
+		{Normal: rl.NewVector2(0, -1), Penetration: movingRec.Y + movingRec.Height - staticRec.Y, Face: FaceTop},
+		{Normal: rl.NewVector2(0, 1), Penetration: staticRec.Y + staticRec.Height - movingRec.Y, Face: FaceBottom},
+		{Normal: rl.NewVector2(-1, 0), Penetration: movingRec.X + movingRec.Width - staticRec.X, Face: FaceLeft},
+		{Normal: rl.NewVector2(1, 0), Penetration: staticRec.X + staticRec.Width - movingRec.X, Face: FaceRight},
+	}
+
+	manifold := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if candidate.Penetration < manifold.Penetration {
+			manifold = candidate
+		}
+	}
+
+	manifold.ContactRect = getIntersection(movingRec, staticRec)
+
+	return manifold, true
+}
+
+// GetAxisCollisionManifold separates the rectangles along a single axis, against the
+// direction the moving rectangle was travelling on it.
+func GetAxisCollisionManifold(movingRec rl.Rectangle, staticRec rl.Rectangle, axis Axis, motion float32) (Manifold, bool) {
+	if !isOverlapping(movingRec, staticRec) {
+		return Manifold{Face: NoFace}, false
+	}
+
+	var manifold Manifold
+
+	if axis == AxisX {
+		movingCenter := movingRec.X + movingRec.Width/2
+		staticCenter := staticRec.X + staticRec.Width/2
+
+		if motion > 0 || (motion == 0 && movingCenter < staticCenter) {
+			manifold = Manifold{Normal: rl.NewVector2(-1, 0), Penetration: movingRec.X + movingRec.Width - staticRec.X, Face: FaceLeft}
+		} else {
+			manifold = Manifold{Normal: rl.NewVector2(1, 0), Penetration: staticRec.X + staticRec.Width - movingRec.X, Face: FaceRight}
+		}
+	} else {
+		movingCenter := movingRec.Y + movingRec.Height/2
+		staticCenter := staticRec.Y + staticRec.Height/2
+
+		if motion > 0 || (motion == 0 && movingCenter < staticCenter) {
+			manifold = Manifold{Normal: rl.NewVector2(0, -1), Penetration: movingRec.Y + movingRec.Height - staticRec.Y, Face: FaceTop}
+		} else {
+			manifold = Manifold{Normal: rl.NewVector2(0, 1), Penetration: staticRec.Y + staticRec.Height - movingRec.Y, Face: FaceBottom}
+		}
+	}
+
+	manifold.ContactRect = getIntersection(movingRec, staticRec)
+
+	return manifold, true
+}
+
+// MoveAndResolve moves the rectangle one axis at a time, pushing it out of every solid it
+// sinks into before moving along the next one. Resolving the axes separately is what keeps
+// a rectangle sliding along a floor or a wall from snagging on it. One way colliders only
+// stop the rectangle when it comes from above them, and slopes lift it onto their surface.
+// A rectangle that sticks to the ground is pulled down onto it after moving sideways.
+func MoveAndResolve(movingRec rl.Rectangle, motion rl.Vector2, colliders []*Collider, stickToGround bool) (rl.Rectangle, Contacts) {
+	movingRec.X += motion.X
+	for _, collider := range colliders {
+		if collider.Kind != SolidCollider {
+			continue
+		}
+
+		if manifold, hits := GetAxisCollisionManifold(movingRec, *collider.Rect, AxisX, motion.X); hits {
+			movingRec.X += manifold.Normal.X * manifold.Penetration
+		}
+	}
+
+	previousBottom := movingRec.Y + movingRec.Height
+	movingRec.Y += motion.Y
+	for _, collider := range colliders {
+		if collider.Kind == SlopeCollider {
+			continue
+		}
+
+		if collider.Kind == OneWayCollider && (motion.Y < 0 || !collider.BlocksFromAbove(previousBottom)) {
+			continue
+		}
+
+		if manifold, hits := GetAxisCollisionManifold(movingRec, *collider.Rect, AxisY, motion.Y); hits {
+			movingRec.Y += manifold.Normal.Y * manifold.Penetration
+		}
+	}
+
+	movingRec = ResolveSlopes(movingRec, motion.Y, colliders)
+
+	if stickToGround && motion.Y >= 0 {
+		movingRec = SnapToGround(movingRec, colliders, float32(math.Abs(float64(motion.X)))+CONTACT_TOLERANCE)
+	}
+
+	return movingRec, GetContacts(movingRec, colliders)
+}
+
+// GetContacts probes every side of the rectangle by CONTACT_TOLERANCE to find out which
+// ones are touching a collider. One way colliders can only be stood on.
+func GetContacts(rec rl.Rectangle, colliders []*Collider) Contacts {
+	var contacts Contacts
+
+	probe := func(offsetX float32, offsetY float32, kind ColliderKind) bool {
+		shifted := rl.NewRectangle(rec.X+offsetX, rec.Y+offsetY, rec.Width, rec.Height)
+
+		for _, collider := range colliders {
+			if collider.Kind != kind {
+				continue
+			}
+
+			if isOverlapping(shifted, *collider.Rect) && !isOverlapping(rec, *collider.Rect) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	onSolidGround := probe(0, CONTACT_TOLERANCE, SolidCollider)
+	onOneWayGround := probe(0, CONTACT_TOLERANCE, OneWayCollider)
+
+	bottom := rec.Y + rec.Height
+	for _, collider := range colliders {
+		if collider.Kind != SlopeCollider {
+			continue
+		}
+
+		surfaceY, isAbove := collider.GetHighestSurfaceY(rec.X, rec.X+rec.Width)
+		if isAbove && math.Abs(float64(surfaceY-bottom)) <= float64(CONTACT_TOLERANCE) {
+			contacts.OnSlope = true
+		}
+	}
+
+	contacts.Ground = onSolidGround || onOneWayGround || contacts.OnSlope
+	contacts.OnOneWay = onOneWayGround && !onSolidGround && !contacts.OnSlope
+	contacts.Ceiling = probe(0, -CONTACT_TOLERANCE, SolidCollider)
+	contacts.LeftWall = probe(-CONTACT_TOLERANCE, 0, SolidCollider)
+	contacts.RightWall = probe(CONTACT_TOLERANCE, 0, SolidCollider)
+
+	return contacts
+}
+
+func isOverlapping(a rl.Rectangle, b rl.Rectangle) bool {
+	return a.X < b.X+b.Width && a.X+a.Width > b.X &&
+		a.Y < b.Y+b.Height && a.Y+a.Height > b.Y
+}
+
+func getIntersection(a rl.Rectangle, b rl.Rectangle) rl.Rectangle {
+	left := max(a.X, b.X)
+	top := max(a.Y, b.Y)
+	right := min(a.X+a.Width, b.X+b.Width)
+	bottom := min(a.Y+a.Height, b.Y+b.Height)
+
+	return rl.NewRectangle(left, top, max(0, right-left), max(0, bottom-top))
+}
@@ -0,0 +1,113 @@
+package collisions
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func solids(rects ...rl.Rectangle) []*Collider {
+	var colliders []*Collider
+	for i := range rects {
+		colliders = append(colliders, NewCollider(&rects[i], SolidCollider))
+	}
+
+	return colliders
+}
+
+func TestMoveAndResolve(t *testing.T) {
+	box := rl.NewRectangle(0, 0, 8, 8)
+
+	for _, testCase := range []struct {
+		name          string
+		motion        rl.Vector2
+		colliders     []*Collider
+		stickToGround bool
+		position      rl.Vector2
+		contacts      Contacts
+	}{
+		{
+			name:      "landing on a corner",
+			motion:    rl.NewVector2(4, 4),
+			colliders: solids(rl.NewRectangle(10, 10, 8, 8)),
+			position:  rl.NewVector2(4, 2),
+			contacts:  Contacts{Ground: true},
+		},
+		{
+			name:      "grazing a corner",
+			motion:    rl.NewVector2(4, 4),
+			colliders: solids(rl.NewRectangle(12, 8, 8, 8)),
+			position:  rl.NewVector2(4, 4),
+			contacts:  Contacts{RightWall: true},
+		},
+		{
+			name:      "falling along a wall",
+			motion:    rl.NewVector2(4, 5),
+			colliders: solids(rl.NewRectangle(10, 0, 8, 24)),
+			position:  rl.NewVector2(2, 5),
+			contacts:  Contacts{RightWall: true},
+		},
+		{
+			name:      "falling into a floor and a wall",
+			motion:    rl.NewVector2(-4, 5),
+			colliders: solids(rl.NewRectangle(-10, -8, 8, 24), rl.NewRectangle(-10, 10, 24, 8)),
+			position:  rl.NewVector2(-2, 2),
+			contacts:  Contacts{Ground: true, LeftWall: true},
+		},
+		{
+			name:      "jumping into a ceiling",
+			motion:    rl.NewVector2(0, -5),
+			colliders: solids(rl.NewRectangle(-4, -11, 16, 8)),
+			position:  rl.NewVector2(0, -3),
+			contacts:  Contacts{Ceiling: true},
+		},
+		{
+			name:          "sticking to a step down",
+			motion:        rl.NewVector2(8, 0),
+			colliders:     solids(rl.NewRectangle(0, 8, 8, 8), rl.NewRectangle(8, 10, 16, 8)),
+			stickToGround: true,
+			position:      rl.NewVector2(8, 2),
+			contacts:      Contacts{Ground: true, LeftWall: true},
+		},
+		{
+			name:      "walking off a step down",
+			motion:    rl.NewVector2(8, 0),
+			colliders: solids(rl.NewRectangle(0, 8, 8, 8), rl.NewRectangle(8, 10, 16, 8)),
+			position:  rl.NewVector2(8, 0),
+		},
+		{
+			name:          "sticking does not reach a drop",
+			motion:        rl.NewVector2(2, 0),
+			colliders:     solids(rl.NewRectangle(10, 20, 16, 8)),
+			stickToGround: true,
+			position:      rl.NewVector2(2, 0),
+		},
+	} {
+		resolved, contacts := MoveAndResolve(box, testCase.motion, testCase.colliders, testCase.stickToGround)
+
+		if resolved.X != testCase.position.X || resolved.Y != testCase.position.Y {
+			t.Errorf("%s: expected the box at %v, got %v", testCase.name, testCase.position, rl.NewVector2(resolved.X, resolved.Y))
+		}
+
+		if contacts != testCase.contacts {
+			t.Errorf("%s: expected contacts %+v, got %+v", testCase.name, testCase.contacts, contacts)
+		}
+	}
+}
+
+func TestGetContactsOnOneWay(t *testing.T) {
+	platform := rl.NewRectangle(0, 8, 16, 8)
+	floor := rl.NewRectangle(8, 8, 16, 8)
+	box := rl.NewRectangle(0, 0, 8, 8)
+
+	oneWay := []*Collider{NewCollider(&platform, OneWayCollider)}
+	if contacts := GetContacts(box, oneWay); !contacts.Ground || !contacts.OnOneWay {
+		t.Fatalf("expected to stand on the one way platform, got %+v", contacts)
+	}
+
+	// half on solid ground is not standing on a one way platform, it can not be dropped from
+	mixed := append(oneWay, NewCollider(&floor, SolidCollider))
+	if contacts := GetContacts(rl.NewRectangle(4, 0, 8, 8), mixed); !contacts.Ground || contacts.OnOneWay {
+		t.Fatalf("expected solid ground to win over the platform, got %+v", contacts)
+	}
+}
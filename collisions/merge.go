@@ -0,0 +1,73 @@
+package collisions
+
+import (
+	"math"
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// MergeGridRectangles greedily merges grid aligned cells into bigger rectangles, first
+// joining contiguous cells of every row into runs and then stacking runs with the same
+// span on consecutive rows. Walkable surfaces end up being a single face, so no seams
+// between tiles are left for the player to trip over.
+func MergeGridRectangles(cells []rl.Rectangle, cellSize float32) []rl.Rectangle {
+	rows := make(map[int32][]int32)
+	for _, cell := range cells {
+		for x := toCell(cell.X, cellSize); x < toCell(cell.X+cell.Width, cellSize); x++ {
+			for y := toCell(cell.Y, cellSize); y < toCell(cell.Y+cell.Height, cellSize); y++ {
+				rows[y] = append(rows[y], x)
+			}
+		}
+	}
+
+	rowIndexes := make([]int32, 0, len(rows))
+	for y := range rows {
+		rowIndexes = append(rowIndexes, y)
+	}
+	slices.Sort(rowIndexes)
+
+	var merged []rl.Rectangle
+	// runs from the previous row that can still grow downwards, keyed by their span
+	open := make(map[[2]int32]int)
+
+	for _, y := range rowIndexes {
+		columns := rows[y]
+		slices.Sort(columns)
+		columns = slices.Compact(columns)
+
+		stillOpen := make(map[[2]int32]int)
+		for start := 0; start < len(columns); {
+			end := start
+			for end+1 < len(columns) && columns[end+1] == columns[end]+1 {
+				end++
+			}
+
+			span := [2]int32{columns[start], columns[end]}
+			if index, ok := open[span]; ok && toCell(merged[index].Y+merged[index].Height, cellSize) == y {
+				merged[index].Height += cellSize
+				stillOpen[span] = index
+			} else {
+				merged = append(merged, rl.NewRectangle(
+					float32(span[0])*cellSize,
+					float32(y)*cellSize,
+					float32(span[1]-span[0]+1)*cellSize,
+					cellSize,
+				))
+				stillOpen[span] = len(merged) - 1
+			}
+
+			start = end + 1
+		}
+
+		open = stillOpen
+	}
+
+	return merged
+}
+
+// toCell rounds down like SpatialGrid.GetCellRange does, so cells left or above the origin
+// are not pulled into the column or row next to it.
+func toCell(position float32, cellSize float32) int32 {
+	return int32(math.Floor(float64(position / cellSize)))
+}
@@ -0,0 +1,121 @@
+package collisions
+
+import (
+	"slices"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// cells lays out 8x8 cells at the given column and row pairs.
+func cells(positions ...[2]float32) []rl.Rectangle {
+	var rects []rl.Rectangle
+	for _, position := range positions {
+		rects = append(rects, rl.NewRectangle(position[0]*8, position[1]*8, 8, 8))
+	}
+
+	return rects
+}
+
+func TestMergeGridRectangles(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		cells    []rl.Rectangle
+		expected []rl.Rectangle
+	}{
+		{
+			name:     "nothing to merge",
+			cells:    nil,
+			expected: nil,
+		},
+		{
+			name:  "row",
+			cells: cells([2]float32{0, 0}, [2]float32{1, 0}, [2]float32{2, 0}),
+			expected: []rl.Rectangle{
+				rl.NewRectangle(0, 0, 24, 8),
+			},
+		},
+		{
+			name:  "column",
+			cells: cells([2]float32{3, 1}, [2]float32{3, 2}, [2]float32{3, 3}),
+			expected: []rl.Rectangle{
+				rl.NewRectangle(24, 8, 8, 24),
+			},
+		},
+		{
+			name:  "L shape",
+			cells: cells([2]float32{0, 0}, [2]float32{0, 1}, [2]float32{0, 2}, [2]float32{1, 2}, [2]float32{2, 2}),
+			expected: []rl.Rectangle{
+				rl.NewRectangle(0, 0, 8, 16),
+				rl.NewRectangle(0, 16, 24, 8),
+			},
+		},
+		{
+			name: "hollow square",
+			cells: cells(
+				[2]float32{0, 0}, [2]float32{1, 0}, [2]float32{2, 0},
+				[2]float32{0, 1}, [2]float32{2, 1},
+				[2]float32{0, 2}, [2]float32{1, 2}, [2]float32{2, 2},
+			),
+			expected: []rl.Rectangle{
+				rl.NewRectangle(0, 0, 24, 8),
+				rl.NewRectangle(0, 8, 8, 8),
+				rl.NewRectangle(16, 8, 8, 8),
+				rl.NewRectangle(0, 16, 24, 8),
+			},
+		},
+		{
+			name:  "duplicate cells",
+			cells: cells([2]float32{0, 0}, [2]float32{1, 0}, [2]float32{0, 0}, [2]float32{0, 1}, [2]float32{1, 1}, [2]float32{1, 1}),
+			expected: []rl.Rectangle{
+				rl.NewRectangle(0, 0, 16, 16),
+			},
+		},
+		{
+			name:  "rows that do not touch",
+			cells: cells([2]float32{0, 0}, [2]float32{1, 0}, [2]float32{0, 2}, [2]float32{1, 2}),
+			expected: []rl.Rectangle{
+				rl.NewRectangle(0, 0, 16, 8),
+				rl.NewRectangle(0, 16, 16, 8),
+			},
+		},
+		{
+			name:  "cells on both sides of the origin",
+			cells: cells([2]float32{-1, -1}, [2]float32{0, -1}, [2]float32{-1, 0}),
+			expected: []rl.Rectangle{
+				rl.NewRectangle(-8, -8, 16, 8),
+				rl.NewRectangle(-8, 0, 8, 8),
+			},
+		},
+		{
+			name:  "negative column stacked over negative rows",
+			cells: cells([2]float32{-2, -3}, [2]float32{-2, -2}, [2]float32{-2, -1}),
+			expected: []rl.Rectangle{
+				rl.NewRectangle(-16, -24, 8, 24),
+			},
+		},
+		{
+			name: "cells a hair off the grid around the origin",
+			cells: []rl.Rectangle{
+				rl.NewRectangle(-7.999, -7.999, 8, 8),
+				rl.NewRectangle(0.001, -7.999, 8, 8),
+			},
+			expected: []rl.Rectangle{
+				rl.NewRectangle(-8, -8, 16, 8),
+			},
+		},
+		{
+			name:  "cells bigger than the grid",
+			cells: []rl.Rectangle{rl.NewRectangle(0, 0, 16, 16), rl.NewRectangle(16, 0, 8, 16)},
+			expected: []rl.Rectangle{
+				rl.NewRectangle(0, 0, 24, 16),
+			},
+		},
+	} {
+		merged := MergeGridRectangles(testCase.cells, 8)
+
+		if !slices.Equal(merged, testCase.expected) {
+			t.Errorf("%s: expected %v, got %v", testCase.name, testCase.expected, merged)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package collisions
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// GetSurfaceY returns the height of the slope surface at x, clamped to the collider.
+func (c *Collider) GetSurfaceY(x float32) float32 {
+	progress := (x - c.Rect.X) / c.Rect.Width
+	progress = min(max(progress, 0), 1)
+
+	height := c.LeftHeight + (c.RightHeight-c.LeftHeight)*progress
+
+	return c.Rect.Y + c.Rect.Height - height
+}
+
+// GetHighestSurfaceY returns the highest point of the slope between left and right. A box
+// resting on a slope is held by that point, which is what lets it walk from a slope onto
+// the flat ground at its top without bumping into it.
+func (c *Collider) GetHighestSurfaceY(left float32, right float32) (float32, bool) {
+	if left >= c.Rect.X+c.Rect.Width || right <= c.Rect.X {
+		return 0, false
+	}
+
+	start := max(left, c.Rect.X)
+	end := min(right, c.Rect.X+c.Rect.Width)
+
+	return min(c.GetSurfaceY(start), c.GetSurfaceY(end)), true
+}
+
+// CheckSlopeCollision tells how deep the feet of the moving rectangle are into the slope.
+// Only the lower half of the rectangle counts, anything deeper is left for the solid
+// tiles around the slope to deal with.
+func CheckSlopeCollision(movingRec rl.Rectangle, slope *Collider) (Manifold, bool) {
+	surfaceY, isAbove := slope.GetHighestSurfaceY(movingRec.X, movingRec.X+movingRec.Width)
+	if !isAbove {
+		return Manifold{Face: NoFace}, false
+	}
+
+	bottom := movingRec.Y + movingRec.Height
+	penetration := bottom - surfaceY
+
+	if penetration <= 0 || penetration > movingRec.Height/2 {
+		return Manifold{Face: NoFace}, false
+	}
+
+	contactRect := rl.NewRectangle(movingRec.X, surfaceY, movingRec.Width, penetration)
+
+	return Manifold{Normal: rl.NewVector2(0, -1), Penetration: penetration, ContactRect: contactRect, Face: FaceTop}, true
+}
+
+// ResolveSlopes lifts the rectangle onto any slope its feet sank into. Slopes are only
+// walked on, so nothing is resolved while moving upwards.
+func ResolveSlopes(movingRec rl.Rectangle, motionY float32, colliders []*Collider) rl.Rectangle {
+	if motionY < 0 {
+		return movingRec
+	}
+
+	for _, collider := range colliders {
+		if collider.Kind != SlopeCollider {
+			continue
+		}
+
+		if manifold, hits := CheckSlopeCollision(movingRec, collider); hits {
+			movingRec.Y += manifold.Normal.Y * manifold.Penetration
+		}
+	}
+
+	return movingRec
+}
+
+// SnapToGround moves the rectangle down onto the closest surface within distance, so
+// walking down a slope keeps it on the ground instead of falling in small hops.
+func SnapToGround(movingRec rl.Rectangle, colliders []*Collider, distance float32) rl.Rectangle {
+	bottom := movingRec.Y + movingRec.Height
+	closest := distance + 1
+
+	for _, collider := range colliders {
+		var surfaceY float32
+
+		if collider.Kind == SlopeCollider {
+			highest, isAbove := collider.GetHighestSurfaceY(movingRec.X, movingRec.X+movingRec.Width)
+			if !isAbove {
+				continue
+			}
+
+			surfaceY = highest
+		} else {
+			if movingRec.X >= collider.Rect.X+collider.Rect.Width || movingRec.X+movingRec.Width <= collider.Rect.X {
+				continue
+			}
+
+			surfaceY = collider.Rect.Y
+		}
+
+		gap := surfaceY - bottom
+		if gap >= -CONTACT_TOLERANCE && gap < closest {
+			closest = gap
+		}
+	}
+
+	if closest <= distance {
+		movingRec.Y += max(closest, 0)
+	}
+
+	return movingRec
+}
@@ -0,0 +1,147 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"game3/assets"
+)
+
+type AnimationFrame struct {
+	X        float32 `json:"x"`
+	Y        float32 `json:"y"`
+	Duration float32 `json:"duration"`
+	Event    string  `json:"event"`
+}
+
+type AnimationClip struct {
+	Name    string
+	Texture string           `json:"texture"`
+	Width   float32          `json:"width"`
+	Height  float32          `json:"height"`
+	Loop    bool             `json:"loop"`
+	Frames  []AnimationFrame `json:"frames"`
+}
+
+type AnimationLibrary map[string]*AnimationClip
+
+// Animations holds every clip from assets/animations.json, keyed by name.
+var Animations = mustLoadAnimations(assets.ANIMATIONS)
+
+func LoadAnimations(data []byte) (AnimationLibrary, error) {
+	var library AnimationLibrary
+	if err := json.Unmarshal(data, &library); err != nil {
+		return nil, err
+	}
+
+	for name, clip := range library {
+		if len(clip.Frames) == 0 {
+			return nil, fmt.Errorf("animation %s has no frames", name)
+		}
+
+		clip.Name = name
+	}
+
+	return library, nil
+}
+
+func mustLoadAnimations(data []byte) AnimationLibrary {
+	library, err := LoadAnimations(data)
+	if err != nil {
+		panic(fmt.Sprintf("error loading animations: %s", err.Error()))
+	}
+
+	return library
+}
+
+// Animator plays one clip at a time. Frames with no duration hold until another clip is played.
+type Animator struct {
+	Clip       *AnimationClip
+	FrameIndex int
+	Elapsed    float32
+	Finished   bool
+	OnEvent    func(event string)
+}
+
+func NewAnimator(clipName string) *Animator {
+	animator := &Animator{}
+	animator.Play(clipName)
+
+	return animator
+}
+
+// Play switches to the given clip, restarting it only when it is not the one already playing.
+func (a *Animator) Play(clipName string) {
+	clip := Animations[clipName]
+	if clip == a.Clip && !a.Finished {
+		return
+	}
+
+	a.Clip = clip
+	a.FrameIndex = 0
+	a.Elapsed = 0
+	a.Finished = false
+
+	a.emitFrameEvent()
+}
+
+func (a *Animator) Update(delta float32) {
+	if a.Clip == nil || a.Finished {
+		return
+	}
+
+	a.Elapsed += delta
+
+	for {
+		frame := a.Clip.Frames[a.FrameIndex]
+		if frame.Duration <= 0 || a.Elapsed < frame.Duration {
+			return
+		}
+
+		a.Elapsed -= frame.Duration
+
+		if a.FrameIndex == len(a.Clip.Frames)-1 {
+			if !a.Clip.Loop {
+				a.Finished = true
+				return
+			}
+
+			a.FrameIndex = 0
+		} else {
+			a.FrameIndex++
+		}
+
+		a.emitFrameEvent()
+	}
+}
+
+func (a *Animator) emitFrameEvent() {
+	if a.Clip == nil || a.OnEvent == nil {
+		return
+	}
+
+	if event := a.Clip.Frames[a.FrameIndex].Event; event != "" {
+		a.OnEvent(event)
+	}
+}
+
+func (a *Animator) Texture() string {
+	if a.Clip == nil {
+		return "tilemap"
+	}
+
+	return a.Clip.Texture
+}
+
+// SourceRect is the area of the clip's texture holding the current frame.
+func (a *Animator) SourceRect() rl.Rectangle {
+	if a.Clip == nil {
+		return rl.NewRectangle(0, 0, TileSize, TileSize)
+	}
+
+	frame := a.Clip.Frames[a.FrameIndex]
+
+	return rl.NewRectangle(frame.X, frame.Y, a.Clip.Width, a.Clip.Height)
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"testing"
+
+	"game3/input"
+)
+
+func TestAnimatorLoopsAndEmitsEvents(t *testing.T) {
+	animator := NewAnimator("player_run")
+
+	var events int
+	animator.OnEvent = func(event string) {
+		events++
+	}
+
+	// one second of running goes through both frames several times
+	for range SIMULATION_FPS {
+		animator.Update(FIXED_DELTA)
+	}
+
+	if animator.Finished {
+		t.Fatalf("a looping clip should never finish")
+	}
+
+	if events < 5 {
+		t.Fatalf("expected a step event for every frame shown, got %d", events)
+	}
+}
+
+func TestOneShotVFXIsRemovedOnceFinished(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	g.PlayVFX(PlayerJumpVFX, g.Player.Position)
+
+	stepFrames(g, int(SIMULATION_FPS)/2, input.State{})
+
+	if len(g.CurrentVFXs) != 0 {
+		t.Fatalf("expected the jump VFX to be gone after half a second, still %d playing", len(g.CurrentVFXs))
+	}
+}
+
+func TestPlayerClipFollowsState(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	stepFrames(g, int(SIMULATION_FPS), input.State{})
+	stepFrames(g, 10, holding(input.MoveRight))
+
+	if g.Player.State != Running {
+		t.Fatalf("expected the player to be running, got %s", g.Player.State)
+	}
+
+	if g.Player.Animator.Clip.Name != "player_run" {
+		t.Fatalf("expected the run clip, got %s", g.Player.Animator.Clip.Name)
+	}
+}
@@ -0,0 +1,103 @@
+package game
+
+import (
+	"math"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	VIEWPORT_WIDTH          float32 = 320
+	VIEWPORT_HEIGHT         float32 = 180
+	CAMERA_DEADZONE_WIDTH   float32 = 32
+	CAMERA_DEADZONE_HEIGHT  float32 = 48
+	CAMERA_LOOK_AHEAD       float32 = 40
+	CAMERA_LOOK_AHEAD_SPEED float32 = 2
+	CAMERA_FOLLOW_SPEED     float32 = 8
+)
+
+// Camera follows the player around levels bigger than the screen. It is part of the
+// simulation, moved once per step, so replays frame the same shots.
+type Camera struct {
+	Position         rl.Vector2 // top left corner of the view, in level space
+	PreviousPosition rl.Vector2
+	Focus            rl.Vector2 // follows the player once it leaves the deadzone
+	LookAhead        float32
+	Width            float32
+	Height           float32
+}
+
+func NewCamera() *Camera {
+	return &Camera{
+		Width:  VIEWPORT_WIDTH,
+		Height: VIEWPORT_HEIGHT,
+	}
+}
+
+// Follow eases the camera towards the player, looking ahead in the direction it is moving.
+func (c *Camera) Follow(player *Player, level *Level, delta float32) {
+	c.PreviousPosition = c.Position
+
+	target := player.GetCenter()
+	c.Focus.X = rl.Clamp(c.Focus.X, target.X-CAMERA_DEADZONE_WIDTH/2, target.X+CAMERA_DEADZONE_WIDTH/2)
+	c.Focus.Y = rl.Clamp(c.Focus.Y, target.Y-CAMERA_DEADZONE_HEIGHT/2, target.Y+CAMERA_DEADZONE_HEIGHT/2)
+
+	// standing still keeps looking wherever the player was going
+	if player.Velocity.X != 0 {
+		lookAhead := CAMERA_LOOK_AHEAD
+		// knockbacks and wall jumps move against where the player faces
+		if player.Velocity.X < 0 {
+			lookAhead = -lookAhead
+		}
+
+		c.LookAhead += (lookAhead - c.LookAhead) * min(1, CAMERA_LOOK_AHEAD_SPEED*delta)
+	}
+
+	goal := c.clamp(c.goal(), level)
+	c.Position = rl.Vector2Lerp(c.Position, goal, min(1, CAMERA_FOLLOW_SPEED*delta))
+	c.Position = c.clamp(c.Position, level)
+}
+
+// SnapTo puts the camera straight on the player, for when the player appears somewhere new.
+func (c *Camera) SnapTo(player *Player, level *Level) {
+	c.Focus = player.GetCenter()
+	c.LookAhead = 0
+	c.Position = c.clamp(c.goal(), level)
+	c.PreviousPosition = c.Position
+}
+
+func (c *Camera) goal() rl.Vector2 {
+	return rl.NewVector2(c.Focus.X+c.LookAhead-c.Width/2, c.Focus.Y-c.Height/2)
+}
+
+// clamp keeps the view inside the level, levels smaller than the view are centered on it.
+func (c *Camera) clamp(position rl.Vector2, level *Level) rl.Vector2 {
+	return rl.NewVector2(
+		clampAxis(position.X, c.Width, float32(level.Width)),
+		clampAxis(position.Y, c.Height, float32(level.Height)),
+	)
+}
+
+func clampAxis(position float32, viewSize float32, levelSize float32) float32 {
+	if levelSize <= viewSize {
+		return (levelSize - viewSize) / 2
+	}
+
+	return rl.Clamp(position, 0, levelSize-viewSize)
+}
+
+// View is the area of the level on screen.
+func (c *Camera) View() rl.Rectangle {
+	return rl.NewRectangle(c.Position.X, c.Position.Y, c.Width, c.Height)
+}
+
+// Camera2D interpolates between steps like the player does, rounding to whole pixels so
+// the tiles do not shimmer while scrolling.
+func (c *Camera) Camera2D(alpha float32) rl.Camera2D {
+	position := rl.Vector2Lerp(c.PreviousPosition, c.Position, alpha)
+
+	return rl.Camera2D{
+		Target: rl.NewVector2(float32(math.Round(float64(position.X))), float32(math.Round(float64(position.Y)))),
+		Zoom:   1,
+	}
+}
@@ -0,0 +1,88 @@
+package game
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestCameraStaysInsideTheLevel(t *testing.T) {
+	level := &Level{Width: 960, Height: 180}
+	player := InitPlayer(RegularCollision)
+	camera := NewCamera()
+
+	player.Teleport(rl.NewVector2(10, 100))
+	camera.SnapTo(player, level)
+	if camera.Position != rl.NewVector2(0, 0) {
+		t.Fatalf("expected the camera against the west edge, got %v", camera.Position)
+	}
+
+	player.Teleport(rl.NewVector2(950, 100))
+	for range SIMULATION_FPS {
+		camera.Follow(player, level, FIXED_DELTA)
+	}
+
+	if camera.Position.X < 960-VIEWPORT_WIDTH-1 || camera.Position.X > 960-VIEWPORT_WIDTH || camera.Position.Y != 0 {
+		t.Fatalf("expected the camera against the east edge, got %v", camera.Position)
+	}
+}
+
+func TestCameraLooksAhead(t *testing.T) {
+	level := &Level{Width: 960, Height: 180}
+	player := InitPlayer(RegularCollision)
+	camera := NewCamera()
+
+	player.Teleport(rl.NewVector2(480, 100))
+	camera.SnapTo(player, level)
+	start := camera.Position.X
+
+	player.Velocity.X = PLAYER_MOVE_SPEED
+	for range SIMULATION_FPS {
+		camera.Follow(player, level, FIXED_DELTA)
+	}
+
+	if camera.Position.X <= start {
+		t.Fatalf("expected the camera to move ahead of a player running east, got %f from %f", camera.Position.X, start)
+	}
+
+	// within the deadzone the player moving around does not move the camera
+	resting := camera.Focus
+	player.Velocity.X = 0
+	player.Teleport(rl.NewVector2(player.Position.X+CAMERA_DEADZONE_WIDTH/4, player.Position.Y))
+	camera.Follow(player, level, FIXED_DELTA)
+
+	if camera.Focus != resting {
+		t.Fatalf("expected the focus to stay while the player is in the deadzone, got %v from %v", camera.Focus, resting)
+	}
+}
+
+func TestCameraCentersSmallLevels(t *testing.T) {
+	level := &Level{Width: 160, Height: 180}
+	player := InitPlayer(RegularCollision)
+	camera := NewCamera()
+
+	camera.SnapTo(player, level)
+	if camera.Position.X != -80 {
+		t.Fatalf("expected a level narrower than the screen to be centered, got %v", camera.Position)
+	}
+}
+
+func TestCameraLooksWhereThePlayerMoves(t *testing.T) {
+	level := &Level{Width: 960, Height: 180}
+	player := InitPlayer(RegularCollision)
+	camera := NewCamera()
+
+	player.Teleport(rl.NewVector2(480, 100))
+	camera.SnapTo(player, level)
+
+	// pushed back west while still facing east, like after a knockback
+	player.FacingDirection = Right
+	player.Velocity.X = -PLAYER_KNOCKBACK_FORCE_X
+	for range SIMULATION_FPS / 2 {
+		camera.Follow(player, level, FIXED_DELTA)
+	}
+
+	if camera.LookAhead >= 0 {
+		t.Fatalf("expected the camera to look ahead west, the way the player moves, got %f", camera.LookAhead)
+	}
+}
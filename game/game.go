@@ -1,11 +1,10 @@
 package game
 
 import (
-	"encoding/json"
 	"fmt"
+	"math/rand"
 
-	"game3/assets"
-	"game3/levels"
+	"game3/input"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
@@ -31,6 +30,13 @@ const (
 	GRAVITY                float32 = 700
 	FALL_TERMINAL_VELOCITY float32 = 300
 	DEBUG                  bool    = false
+
+	// the simulation always advances in steps of FIXED_DELTA, no matter the refresh rate
+	SIMULATION_FPS int32   = 120
+	FIXED_DELTA    float32 = 1 / float32(SIMULATION_FPS)
+
+	// longest frame time fed into the simulation, avoids spiralling after a hitch
+	MAX_FRAME_TIME float32 = 0.25
 )
 
 var gameStateName = map[GameState]string{
@@ -53,90 +59,120 @@ type Game struct {
 	Player                  *Player
 	World                   *World
 	CurrentLevel            *Level
+	Camera                  *Camera
+	TransitionSettings      TransitionSettings
+	RoomTransition          *RoomTransition
 	Renderer                *Renderer
 	DebugMode               bool
 	FrameInspectorMode      bool
-	ActiveGamepad           int32
+	StepRequested           bool
+	PendingInput            input.State
+	Accumulator             float32
 	CurrentVFXs             []*VFX
 	CollisionSystem         CollisionSystem
+	Seed                    int64
+	Random                  *rand.Rand
+	Recording               *Replay
+	Playback                *Replay
 }
 
-type World struct {
-	ID     string   `json:"iid"`
-	Levels []*Level `json:"levels"`
-}
-
-func InitGame(debugMode bool, raycasted bool) *Game {
+// NewGame builds the simulation alone, it needs neither a window nor textures. Two games
+// with the same seed fed the same actions play out exactly the same.
+func NewGame(collisionSystem CollisionSystem, seed int64) *Game {
 	world, loadWorldErr := LoadWorld()
 	if loadWorldErr != nil {
 		panic(fmt.Sprintf("error loading world: %s", loadWorldErr.Error()))
 	}
 
-	// TODO: the keys from the map can probably be infered from the assets file data
-	tilemap := rl.LoadImageFromMemory(".png", assets.TILEMAP, int32(len(assets.TILEMAP)))
-	var textures = map[string]rl.Texture2D{
-		"tilemap": rl.LoadTextureFromImage(tilemap),
+	game := Game{
+		Player:             InitPlayer(collisionSystem),
+		State:              Playing,
+		World:              world,
+		Camera:             NewCamera(),
+		TransitionSettings: DefaultTransitionSettings(),
+		CollisionSystem:    collisionSystem,
+		Seed:               seed,
+		Random:             rand.New(rand.NewSource(seed)),
 	}
 
-	renderer := &Renderer{
-		Textures:  textures,
-		DebugMode: debugMode,
-	}
+	game.Player.OnTransition(game.HandlePlayerTransition)
+	game.Player.Animator.OnEvent = game.HandlePlayerAnimationEvent
+	game.mustLoadLevel("Level_4")
+	game.Camera.SnapTo(game.Player, game.CurrentLevel)
 
+	return &game
+}
 
+func InitGame(debugMode bool, raycasted bool, seed int64) *Game {
 	collisionSystem := RegularCollision
 	if raycasted {
 		collisionSystem = RayCastedCollision
 	}
-	pc := InitPlayer(collisionSystem)
-
-	game := Game{
-		Player:          pc,
-		State:           Playing,
-		World:           world,
-		Renderer:        renderer,
-		DebugMode:       debugMode,
-		CollisionSystem: collisionSystem,
-	}
 
-	game.LoadLevel("Level_4")
+	game := NewGame(collisionSystem, seed)
+	game.Renderer = NewRenderer(debugMode)
+	game.DebugMode = debugMode
 
-	return &game
+	return game
 }
 
 func (game *Game) SetState(state GameState) {
 	if state == Playing {
-		game.LoadLevel("Level_0")
+		game.RoomTransition = nil
+		game.mustLoadLevel("Level_0")
+		game.Camera.SnapTo(game.Player, game.CurrentLevel)
 	}
 
 	game.State = state
 }
 
-func (g *Game) Tick(delta float32) {
+// Advance runs as many simulation steps as fit in the time accumulated so far. The presses
+// and releases of a frame too short to run a step wait for the next step instead.
+func (g *Game) Advance(frameTime float32, actions input.State) {
+	g.Accumulator += frameTime
+
+	stepped := false
+	for g.Accumulator >= FIXED_DELTA {
+		g.Tick(FIXED_DELTA, actions)
+		g.Accumulator -= FIXED_DELTA
+		actions = actions.WithoutEdges()
+		stepped = true
+	}
+
+	if !stepped {
+		g.PendingInput = actions.Merge(g.PendingInput)
+	}
+}
+
+func (g *Game) Tick(delta float32, actions input.State) {
 	if g.State == MainMenu {
 		return
 	}
 
-	g.Render()
 	g.IncreaseFrameCount()
-
-	g.DetectActiveGamepad()
-	g.ProcessInput()
 	g.CheckRoomChange()
 
 	if g.CanTick() {
-		if g.Player.LastAction == Jump {
-			g.PlayVFX(PlayerJumpVFX, g.Player.Position)
+		actions = actions.Merge(g.PendingInput)
+		g.PendingInput = input.State{}
+
+		if g.Playback != nil {
+			actions = g.NextPlaybackActions()
 		}
 
-		if g.Player.IsDead {
-			g.PlayVFX(PlayerDeathVFX, g.Player.Position)
-			g.Reset()
-			g.Player.IsDead = false
+		if g.Recording != nil {
+			g.Recording.Record(actions)
 		}
 
-		g.Player.Tick(delta, g.CurrentLevel, g.ActiveGamepad)
-		g.CurrentLevel.Tick(delta)
+		if g.RoomTransition != nil {
+			// the player is frozen until the new room is in view
+			g.UpdateRoomTransition()
+		} else {
+			g.Simulate(delta, actions)
+		}
+	} else {
+		// presses and releases wait for the next step instead of getting lost
+		g.PendingInput = actions.Merge(g.PendingInput)
 	}
 
 	if g.DebugMode {
@@ -144,53 +180,103 @@ func (g *Game) Tick(delta float32) {
 	}
 }
 
-func (g *Game) ProcessInput() {
-	if rl.IsKeyReleased(rl.KeyI) {
-		g.FrameInspectorMode = !g.FrameInspectorMode
+func (g *Game) Simulate(delta float32, actions input.State) {
+	if actions.IsPressed(input.Reset) {
+		g.Reset()
 	}
-}
 
-func LoadWorld() (*World, error) {
-	var world World
-	json.Unmarshal(levels.LEVELS, &world)
+	if g.Player.State == Dashing && g.Player.DashTimer%PLAYER_DASH_TRAIL_FRAMES == 0 {
+		g.PlayVFX(PlayerDashVFX, g.Player.Position)
+	}
 
-	return &world, nil
+	if g.Player.State == Dead {
+		g.Reset()
+	}
+
+	g.Player.Tick(delta, g.CurrentLevel, actions)
+	g.CurrentLevel.Tick(delta)
+	g.UpdateCurrentVFXs(delta)
+	g.Camera.Follow(g.Player, g.CurrentLevel, delta)
 }
 
-func (g *Game) LoadLevel(levelName string) {
-	var currentLevel *Level
-	for _, level := range g.World.Levels {
-		if level.Name == levelName {
-			currentLevel = level
-			break
+// ProcessInput handles the input that has to be read once per rendered frame instead of
+// once per simulation step.
+func (g *Game) ProcessInput(actions input.State) {
+	if actions.IsPressed(input.Pause) {
+		if g.State == Playing {
+			g.State = Paused
+		} else if g.State == Paused {
+			g.State = Playing
 		}
 	}
 
-	currentLevel.Load()
-	g.CurrentLevel = currentLevel
+	if actions.IsReleased(input.ToggleInspector) {
+		g.FrameInspectorMode = !g.FrameInspectorMode
+	}
+
+	if g.FrameInspectorMode && actions.IsReleased(input.StepFrame) {
+		g.StepRequested = true
+	}
+}
+
+// LoadLevel makes the level current, loading it from the world.
+func (g *Game) LoadLevel(levelName string) error {
+	level := g.World.FindLevel(levelName)
+	if level == nil {
+		return &WorldError{Kind: UnknownLevel, Level: levelName}
+	}
+
+	level.Random = g.Random
+	if err := level.Load(); err != nil {
+		return err
+	}
+
+	g.CurrentLevel = level
+
+	return nil
+}
+
+// mustLoadLevel is for the levels the game itself asks for, the world was validated when
+// it was loaded so they can only be missing by mistake.
+func (g *Game) mustLoadLevel(levelName string) {
+	if err := g.LoadLevel(levelName); err != nil {
+		panic(fmt.Sprintf("error loading level: %s", err.Error()))
+	}
 }
 
 func (g *Game) FindLevelNameFromID(levelID string) string {
-	for _, level := range g.World.Levels {
-		if level.ID == levelID {
-			return level.Name
-		}
+	if level := g.World.FindLevelByID(levelID); level != nil {
+		return level.Name
 	}
 
 	return ""
 }
 
-func (g *Game) Render() {
+// Render draws the current state, alpha being how far the accumulated time has gone
+// towards the next simulation step.
+func (g *Game) Render(alpha float32) {
+	camera := g.Camera.Camera2D(alpha)
+
+	if g.RoomTransition != nil {
+		g.RoomTransition.DrawPreviousRoom(g.Renderer, camera)
+		defer g.RoomTransition.DrawOverlay(alpha)
+	}
+
+	rl.BeginMode2D(camera)
+	defer rl.EndMode2D()
+
 	g.CurrentLevel.DrawLayer("Background", g.Renderer)
 	g.CurrentLevel.DrawLayer("BackgroundProps", g.Renderer)
 	g.CurrentLevel.DrawLayer("Ground", g.Renderer)
 	g.CurrentLevel.DrawProps(g.Renderer)
-	g.Player.Draw(g.Renderer)
+	g.Player.Draw(g.Renderer, alpha)
+	g.CurrentLevel.DrawWater(g.Renderer)
 	g.DrawCurrentVFXs()
 	g.CurrentLevel.DrawParticles(g.Renderer)
 	g.CurrentLevel.DrawLayer("ForegroundProps", g.Renderer)
 
 	if g.DebugMode {
+		g.CurrentLevel.DrawCollisionables(g.Renderer)
 		g.Player.DrawHitbox()
 
 		// playerPosition := rl.NewVector2(g.Player.Position.X, g.Player.Position.Y)
@@ -212,41 +298,70 @@ func (g *Game) Render() {
 	}
 }
 
+// CheckRoomChange moves the player into the neighbour under the point where it left the
+// level, keeping its place in the world. With nothing there it stays in the current level.
 func (g *Game) CheckRoomChange() {
-	var direction string
+	if !g.Player.HasLeftLevel() {
+		return
+	}
+
+	exitPoint := g.CurrentLevel.ToWorld(g.Player.GetCenter())
+	next := g.World.FindNeighbourAt(g.CurrentLevel, exitPoint)
 
-	if g.Player.WentNorth {
-		direction = "n"
+	if next == nil {
+		g.Player.KeepInside(g.CurrentLevel)
+	} else {
+		g.ChangeRoom(next)
 	}
 
-	if g.Player.WentEast {
-		direction = "e"
+	g.Player.WentNorth = false
+	g.Player.WentEast = false
+	g.Player.WentSouth = false
+	g.Player.WentWest = false
+}
+
+// ChangeRoom makes the level current and starts the transition into it, everything that
+// lives in level space is carried over into the space of the new room.
+func (g *Game) ChangeRoom(next *Level) {
+	previous := g.CurrentLevel
+	offset := rl.Vector2Subtract(previous.ToWorld(rl.Vector2Zero()), next.ToWorld(rl.Vector2Zero()))
+	fromCamera := rl.Vector2Add(g.Camera.Position, offset)
+
+	g.mustLoadLevel(next.Name)
+	g.Player.MoveBy(offset)
+	g.Player.Path = make([]rl.Vector2, 20)
+
+	for _, vfx := range g.CurrentVFXs {
+		vfx.Position = rl.Vector2Add(vfx.Position, offset)
 	}
 
-	if g.Player.WentSouth {
-		direction = "s"
+	g.Camera.SnapTo(g.Player, g.CurrentLevel)
+
+	if g.TransitionSettings.Type == CutTransition || g.TransitionSettings.Frames <= 0 {
+		previous.Unload()
+		return
 	}
 
-	if g.Player.WentWest {
-		direction = "w"
+	g.RoomTransition = &RoomTransition{
+		Type:       g.TransitionSettings.Type,
+		From:       previous,
+		Offset:     offset,
+		FromCamera: fromCamera,
+		ToCamera:   g.Camera.Position,
+		Duration:   g.TransitionSettings.Frames,
 	}
 
-	for _, neighbour := range g.CurrentLevel.Neighbours {
-		if neighbour.Direction == direction {
-			levelName := g.FindLevelNameFromID(neighbour.LevelID)
+	g.Camera.Position = fromCamera
+	g.Camera.PreviousPosition = fromCamera
+}
 
-			g.CurrentLevel.Unload()
-			g.LoadLevel(levelName)
-			g.Player.Path = make([]rl.Vector2, 20)
+func (g *Game) UpdateRoomTransition() {
+	g.RoomTransition.Tick(g.Camera)
 
-			break
-		}
+	if g.RoomTransition.IsDone() {
+		g.RoomTransition.From.Unload()
+		g.RoomTransition = nil
 	}
-
-	g.Player.WentNorth = false
-	g.Player.WentEast = false
-	g.Player.WentSouth = false
-	g.Player.WentWest = false
 }
 
 func (game *Game) LogState() {
@@ -254,7 +369,6 @@ func (game *Game) LogState() {
 	// rl.TraceLog(rl.LogInfo, "frame: %d", game.AbsoluteFrame)
 	// rl.TraceLog(rl.LogInfo, "player.Velocity: %f", game.Player.Velocity)
 	// rl.TraceLog(rl.LogInfo, "collisionable.Position: %f", game.CurrentLevel.CollisionableHitboxes[game.CurrentLevel.PlayerCollisionIndex])
-	// rl.TraceLog(rl.LogInfo, "player.FramesCounter: %d", game.Player.FramesCounter)
 	// rl.TraceLog(rl.LogInfo, "player.Position.X: %f", game.Player.Position.X)
 	// rl.TraceLog(rl.LogInfo, "player.Position.Y: %f", game.Player.Position.Y)
 	// rl.TraceLog(rl.LogInfo, "player.WentNorth: %t", game.Player.WentNorth)
@@ -277,12 +391,65 @@ func (game *Game) LogState() {
 	/// rl.TraceLog(rl.LogInfo, "FrameInspectorMode: %t", game.FrameInspectorMode)
 }
 
+func (g *Game) StartRecording() {
+	profile := g.Player.Profile
+	transition := g.TransitionSettings
+
+	g.Recording = &Replay{
+		Level:           g.CurrentLevel.Name,
+		Position:        g.Player.Position,
+		Seed:            g.Seed,
+		CollisionSystem: g.CollisionSystem,
+		Profile:         &profile,
+		Transition:      &transition,
+	}
+}
+
+// StartPlayback puts the game back where the replay was recorded, it expects a game
+// freshly built with the replay seed.
+func (g *Game) StartPlayback(replay *Replay) error {
+	// loading the level again has to draw the same random numbers it drew when recording
+	g.Random.Seed(replay.Seed)
+	if err := g.LoadLevel(replay.Level); err != nil {
+		return err
+	}
+
+	g.Player.Teleport(replay.Position)
+	g.Camera.SnapTo(g.Player, g.CurrentLevel)
+	g.Playback = replay
+
+	// replays recorded before profiles existed keep whichever one is loaded
+	if replay.Profile != nil {
+		g.Player.Profile = *replay.Profile
+	}
+
+	// and before transitions existed rooms changed straight away
+	g.TransitionSettings = TransitionSettings{Type: CutTransition}
+	if replay.Transition != nil {
+		g.TransitionSettings = *replay.Transition
+	}
+
+	return nil
+}
+
+// NextPlaybackActions hands control back to the player once the replay runs out of frames.
+func (g *Game) NextPlaybackActions() input.State {
+	actions, ok := g.Playback.Next()
+	if !ok {
+		rl.TraceLog(rl.LogInfo, "replay finished after %d frames", len(g.Playback.Frames))
+		g.Playback = nil
+	}
+
+	return actions
+}
+
 func (g *Game) IncreaseFrameCount() {
 	g.AbsoluteFrame += 1
 }
 
 func (g *Game) CanTick() bool {
-	if g.FrameInspectorMode && rl.IsKeyReleased(rl.KeyN) {
+	if g.FrameInspectorMode && g.StepRequested {
+		g.StepRequested = false
 		return true
 	}
 
@@ -294,42 +461,75 @@ func (g *Game) CanTick() bool {
 }
 
 func (game *Game) Reset() {
-	game.LoadLevel("Level_2")
-	game.Player.Position = rl.NewVector2(147, 82)
-	game.Player.Velocity.Y = 0
-	game.Player.OnGround = false
+	game.mustLoadLevel("Level_2")
+	game.Player.Respawn(rl.NewVector2(147, 82))
+	game.Camera.SnapTo(game.Player, game.CurrentLevel)
 }
 
-func (game *Game) DetectActiveGamepad() {
-	for i := range int32(4) {
-		if rl.IsGamepadAvailable(i) {
-			game.ActiveGamepad = i
-			return
+// HandlePlayerTransition plays the effects and sounds that go with the player changing state.
+func (game *Game) HandlePlayerTransition(player *Player, from PlayerState, to PlayerState) {
+	// dashing or getting hit under water is still swimming, only crossing the surface splashes
+	enteredWater := to == Swimming && from != Dashing && from != Knockback
+	leftWater := from == Swimming && !player.IsSwimming()
+	if enteredWater || leftWater {
+		game.Splash(rl.NewVector2(player.Position.X, player.WaterSurface))
+	}
+
+	switch to {
+	case Jumping:
+		// any other way into Jumping is not a jump, like a dash or a hit sending the player up
+		if from == Idle || from == Running || from == Landing || from == Falling || from == Climbing {
+			game.PlayVFX(PlayerJumpVFX, player.Position)
+			game.PlaySound(JumpSound)
 		}
+	case WallJumping:
+		game.PlayVFX(PlayerWallJumpVFX, player.GetWallJumpVFXPosition())
+		game.PlaySound(JumpSound)
+	case Landing:
+		game.PlayVFX(PlayerLandVFX, player.Position)
+		game.PlaySound(LandSound)
+	case Dashing:
+		game.PlaySound(DashSound)
+	case Knockback:
+		game.PlaySound(HurtSound)
+	case Dead:
+		game.PlayVFX(PlayerDeathVFX, player.Position)
+		game.PlaySound(DeathSound)
 	}
 }
 
+// HandlePlayerAnimationEvent reacts to the events tagged on the frames of the player clips.
+func (game *Game) HandlePlayerAnimationEvent(event string) {
+	switch event {
+	case "step":
+		game.PlaySound(StepSound)
+	}
+}
+
+func (game *Game) Splash(position rl.Vector2) {
+	game.PlayVFX(SplashVFX, rl.NewVector2(position.X, position.Y-TileSize))
+	game.CurrentLevel.SpawnSplash(position)
+	game.PlaySound(SplashSound)
+}
+
 func (game *Game) PlayVFX(vfxType VFXType, position rl.Vector2) {
 	vfx := NewVFX(vfxType, position)
 	game.CurrentVFXs = append(game.CurrentVFXs, &vfx)
 }
 
-func (game *Game) DrawCurrentVFXs() {
-	for i, vfx := range game.CurrentVFXs {
-		game.Renderer.DrawVFX(vfx)
-		vfx.AnimationCurrentFrame += 1
+func (game *Game) UpdateCurrentVFXs(delta float32) {
+	for i := len(game.CurrentVFXs) - 1; i >= 0; i-- {
+		vfx := game.CurrentVFXs[i]
+		vfx.Animator.Update(delta)
 
-		if vfx.AnimationCurrentFrame%vfx.AnimationFramesPerPosition == 0 {
-			vfx.AnimationCurrentPosition += 1
-			vfx.AnimationCurrentFrame = 0
+		if vfx.IsDone() {
+			game.CurrentVFXs = append(game.CurrentVFXs[:i], game.CurrentVFXs[i+1:]...)
 		}
+	}
+}
 
-		if vfx.AnimationCurrentPosition >= vfx.AnimationPositionsCounter {
-			if vfx.Loops {
-				vfx.AnimationCurrentPosition = 0
-			} else {
-				game.CurrentVFXs = append(game.CurrentVFXs[:i], game.CurrentVFXs[i+1:]...)
-			}
-		}
+func (game *Game) DrawCurrentVFXs() {
+	for _, vfx := range game.CurrentVFXs {
+		game.Renderer.DrawVFX(vfx)
 	}
 }
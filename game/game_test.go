@@ -0,0 +1,199 @@
+package game
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"game3/input"
+)
+
+func holding(actions ...input.Action) input.State {
+	var state input.State
+	for _, action := range actions {
+		state.Set(action, true, false, false)
+	}
+
+	return state
+}
+
+func stepFrames(g *Game, frames int, actions input.State) {
+	for range frames {
+		g.Tick(FIXED_DELTA, actions)
+	}
+}
+
+func TestHeadlessSimulation(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+
+	stepFrames(g, int(SIMULATION_FPS), input.State{})
+	if !g.Player.OnGround {
+		t.Fatalf("expected the player to land after a second, position %v", g.Player.Position)
+	}
+
+	start := g.Player.Position
+	stepFrames(g, int(SIMULATION_FPS)/2, holding(input.MoveRight))
+	if g.Player.Position.X <= start.X {
+		t.Fatalf("expected the player to walk right from %v, got %v", start, g.Player.Position)
+	}
+
+	stepFrames(g, 1, pressing(input.Jump))
+	if g.Player.OnGround || g.Player.Velocity.Y >= 0 {
+		t.Fatalf("expected the player to jump, velocity %v", g.Player.Velocity)
+	}
+}
+
+func TestHeadlessRoomChange(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	g.Player.Teleport(rl.NewVector2(316, 84))
+	g.Player.Velocity.X = PLAYER_MOVE_SPEED
+
+	stepFrames(g, int(SIMULATION_FPS)/10, holding(input.MoveRight))
+	if g.CurrentLevel.Name != "Level_1" {
+		t.Fatalf("expected to walk east into Level_1, got %s", g.CurrentLevel.Name)
+	}
+
+	// Level_1 sits right above Level_0, the player keeps its height across the edge
+	if g.Player.Position.X > 8 || g.Player.Position.Y < 80 || g.Player.Position.Y > 90 {
+		t.Fatalf("expected the player on the west edge of Level_1, got %v", g.Player.Position)
+	}
+}
+
+func TestRoomTransitionFreezesThePlayer(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	g.Player.Teleport(rl.NewVector2(316, 84))
+	g.Player.Velocity.X = PLAYER_MOVE_SPEED
+
+	for frame := 0; g.CurrentLevel.Name != "Level_1"; frame++ {
+		if frame > int(SIMULATION_FPS) {
+			t.Fatalf("expected to walk east into Level_1, still in %s", g.CurrentLevel.Name)
+		}
+
+		stepFrames(g, 1, holding(input.MoveRight))
+	}
+
+	if g.RoomTransition == nil {
+		t.Fatalf("expected a room transition to start")
+	}
+
+	// Level_1 is right of Level_4, the camera starts sliding from a full screen to its left
+	if g.Camera.Position.X > -300 || g.Camera.Position.Y != 0 {
+		t.Fatalf("expected the camera still over Level_4, got %v", g.Camera.Position)
+	}
+
+	// the transition took its first step on the frame the room changed
+	entered := g.Player.Position
+	stepFrames(g, int(g.TransitionSettings.Frames)-2, holding(input.MoveRight))
+	if g.Player.Position != entered || g.RoomTransition == nil {
+		t.Fatalf("expected the player frozen during the transition, moved from %v to %v", entered, g.Player.Position)
+	}
+
+	stepFrames(g, 1, holding(input.MoveRight))
+	if g.RoomTransition != nil || g.Camera.Position != rl.NewVector2(0, 0) {
+		t.Fatalf("expected the transition over with the camera on Level_1, got %v", g.Camera.Position)
+	}
+
+	stepFrames(g, 10, holding(input.MoveRight))
+	if g.Player.Position.X <= entered.X {
+		t.Fatalf("expected the player to move again after the transition, got %v", g.Player.Position)
+	}
+}
+
+func TestCutTransitionChangesRoomStraightAway(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	g.TransitionSettings = TransitionSettings{Type: CutTransition}
+	g.Player.Teleport(rl.NewVector2(316, 84))
+	g.Player.Velocity.X = PLAYER_MOVE_SPEED
+
+	stepFrames(g, int(SIMULATION_FPS)/10, holding(input.MoveRight))
+	if g.CurrentLevel.Name != "Level_1" || g.RoomTransition != nil {
+		t.Fatalf("expected to be in Level_1 with no transition, got %s", g.CurrentLevel.Name)
+	}
+}
+
+func TestEdgeWithoutNeighbourBlocksThePlayer(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	g.Player.Teleport(rl.NewVector2(4, 84))
+	g.Player.Velocity.X = -PLAYER_MOVE_SPEED
+
+	stepFrames(g, int(SIMULATION_FPS)/10, holding(input.MoveLeft))
+	if g.CurrentLevel.Name != "Level_4" {
+		t.Fatalf("expected to stay in Level_4, got %s", g.CurrentLevel.Name)
+	}
+
+	if g.Player.Position.X < 0 {
+		t.Fatalf("expected the player to stop at the west edge, got %v", g.Player.Position)
+	}
+}
+
+func TestPressOnFrameWithoutStepIsKept(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	stepFrames(g, int(SIMULATION_FPS), input.State{})
+	if !g.Player.OnGround {
+		t.Fatalf("expected the player to have landed")
+	}
+
+	// a rendered frame shorter than a step, the jump is pressed on it
+	g.Advance(FIXED_DELTA/3, pressing(input.Jump))
+	if g.Player.LastAction == Jump {
+		t.Fatalf("expected no step to run on a frame shorter than a step")
+	}
+
+	g.Advance(FIXED_DELTA, holding(input.Jump))
+	if g.Player.LastAction != Jump {
+		t.Fatalf("expected the jump pressed on the previous frame to be carried over")
+	}
+}
+
+func TestHeadlessReset(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	stepFrames(g, int(SIMULATION_FPS), input.State{})
+
+	g.Reset()
+	stepFrames(g, 1, input.State{})
+
+	if g.CurrentLevel.Name != "Level_2" || g.Player.Position.X != 147 {
+		t.Fatalf("expected the player back at the Level_2 spawn, got %s %v", g.CurrentLevel.Name, g.Player.Position)
+	}
+}
+
+func TestReplayIsDeterministic(t *testing.T) {
+	script := []input.State{}
+	for frame := range 4 * int(SIMULATION_FPS) {
+		actions := holding(input.MoveRight)
+		if frame%90 < 30 {
+			actions = holding(input.MoveRight, input.Jump)
+		}
+		if frame%90 == 30 {
+			actions.Set(input.Jump, false, false, true)
+		}
+
+		script = append(script, actions)
+	}
+
+	recorded := NewGame(RayCastedCollision, 42)
+	recorded.StartRecording()
+	var positions []rl.Vector2
+	for _, actions := range script {
+		recorded.Tick(FIXED_DELTA, actions)
+		positions = append(positions, recorded.Player.Position)
+	}
+
+	replayed := NewGame(recorded.Recording.CollisionSystem, recorded.Recording.Seed)
+	if err := replayed.StartPlayback(recorded.Recording); err != nil {
+		t.Fatalf("error starting the playback: %s", err)
+	}
+	for frame, position := range positions {
+		replayed.Tick(FIXED_DELTA, input.State{})
+
+		if replayed.Player.Position != position {
+			t.Fatalf("frame %d: expected %v, replay got %v", frame, position, replayed.Player.Position)
+		}
+	}
+
+	for i, particle := range replayed.CurrentLevel.Particles {
+		if particle.Position != recorded.CurrentLevel.Particles[i].Position {
+			t.Fatalf("particle %d diverged", i)
+		}
+	}
+}
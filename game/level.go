@@ -1,40 +1,76 @@
 package game
 
 import (
+	"errors"
+	"math"
+	"math/rand"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"game3/collisions"
 )
 
 type Level struct {
-	ID                    string            `json:"iid"`
-	Name                  string            `json:"identifier"`
-	Neighbours            []*LevelNeighbour `json:"__neighbours"`
-	Layers                []*LevelLayer     `json:"layerInstances"`
-	Background            string            `json:"bgRelPath"`
-	Props                 []*Prop
-	Particles             []*Particle
-	CollisionableHitboxes []*rl.Rectangle
-	PlayerCollisionIndex  int
+	ID                   string            `json:"iid"`
+	Name                 string            `json:"identifier"`
+	Neighbours           []*LevelNeighbour `json:"__neighbours"`
+	Layers               []*LevelLayer     `json:"layerInstances"`
+	Background           string            `json:"bgRelPath"`
+	WorldX               int               `json:"worldX"`
+	WorldY               int               `json:"worldY"`
+	Width                int               `json:"pxWid"`
+	Height               int               `json:"pxHei"`
+	Props                []*Prop
+	Particles            []*Particle
+	GroundHitboxes       []rl.Rectangle
+	OneWayHitboxes       []rl.Rectangle
+	HazardHitboxes       []rl.Rectangle
+	WaterAreas           []rl.Rectangle // painted water and water props, built with the colliders
+	Collisionables       []*collisions.Collider
+	CollisionGrid        *collisions.SpatialGrid
+	PlayerCollisionIndex int
+	Random               *rand.Rand
 }
 
 type LevelLayer struct {
 	ID          string        `json:"iid"`
 	Name        string        `json:"__identifier"`
-	TilesetPath []*Tile       `json:"__tilesetRelPath"`
+	Type        string        `json:"__type"`
+	TilesetPath string        `json:"__tilesetRelPath"`
+	GridSize    int           `json:"__gridSize"`
+	Columns     int           `json:"__cWid"`
+	Rows        int           `json:"__cHei"`
 	RawLayout   []*LDtkTile   `json:"gridTiles"`
 	RawEntities []*LDtkEntity `json:"entityInstances"`
+	IntGrid     []int         `json:"intGridCsv"`
 	Entities    []*Prop
 	Layout      []*Tile
 }
 
+// IntGridValue is what a cell of the Collision layer is painted with in LDtk.
+type IntGridValue int
+
+const (
+	EmptyCell IntGridValue = iota
+	SolidCell
+	OneWayCell
+	HazardCell
+	WaterCell
+)
+
 type LevelNeighbour struct {
 	LevelID   string `json:"levelIid"`
 	Direction string `json:"dir"`
 }
 
-func (l *Level) Load() {
+func (l *Level) Load() error {
+	if errs := l.Validate(); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
 	l.GetLayer("Background").LoadLayout()
 	l.GetLayer("BackgroundProps").LoadLayout()
 	l.GetLayer("Ground").LoadLayout()
@@ -46,6 +82,21 @@ func (l *Level) Load() {
 	if l.Background != "" {
 		l.Background = strings.TrimSuffix(filepath.Base(l.Background), filepath.Ext(l.Background))
 	}
+
+	return nil
+}
+
+// WorldRect is the area the level covers in the world.
+func (l *Level) WorldRect() rl.Rectangle {
+	return rl.NewRectangle(float32(l.WorldX), float32(l.WorldY), float32(l.Width), float32(l.Height))
+}
+
+func (l *Level) ToWorld(position rl.Vector2) rl.Vector2 {
+	return rl.NewVector2(position.X+float32(l.WorldX), position.Y+float32(l.WorldY))
+}
+
+func (l *Level) ToLocal(position rl.Vector2) rl.Vector2 {
+	return rl.NewVector2(position.X-float32(l.WorldX), position.Y-float32(l.WorldY))
 }
 
 func (l *Level) Unload() {
@@ -53,29 +104,89 @@ func (l *Level) Unload() {
 }
 
 func (l *Level) Tick(delta float32) {
-	for i, particle := range l.Particles {
-		particle.UpdatePosition(delta)
+	for _, prop := range l.Props {
+		prop.Animator.Update(delta)
+	}
 
-		if particle.FramesToLive < 0 {
-			l.Particles[i] = NewParticle()
+	for i := len(l.Particles) - 1; i >= 0; i-- {
+		particle := l.Particles[i]
+		particle.UpdatePosition(delta, l.Random)
+
+		if particle.FramesToLive >= 0 {
+			continue
+		}
+
+		// splashes are one offs, the ambient particles keep their number
+		if particle.Type == SplashParticle {
+			l.Particles = append(l.Particles[:i], l.Particles[i+1:]...)
+		} else {
+			l.Particles[i] = NewParticle(l.Random, l.Width, l.Height)
 		}
 	}
 }
 
+// Draw draws the whole level on its own, without the player in it.
+func (l *Level) Draw(r *Renderer) {
+	l.DrawLayer("Background", r)
+	l.DrawLayer("BackgroundProps", r)
+	l.DrawLayer("Ground", r)
+	l.DrawProps(r)
+	l.DrawWater(r)
+	l.DrawParticles(r)
+	l.DrawLayer("ForegroundProps", r)
+}
+
 func (level *Level) DrawLayer(layerName string, r *Renderer) {
 	for _, tile := range level.GetLayer(layerName).Layout {
 		// TODO: could I maybe do just r.DrawGroundTile(tile)????/
 		rec := rl.NewRectangle(tile.SpritePosition.X, tile.SpritePosition.Y, 8, 8)
-		r.DrawSprite("tilemap", rec, tile.Position)
+		r.DrawSprite(tile.Tileset, rec, tile.Position)
 	}
 }
 
 func (l *Level) DrawProps(r *Renderer) {
 	for _, prop := range l.Props {
+		if prop.Type == PropWater {
+			continue
+		}
+
 		r.DrawProp(prop)
 	}
 }
 
+// DrawWater goes over the player, so whatever is under the surface looks submerged.
+func (l *Level) DrawWater(r *Renderer) {
+	for _, area := range l.WaterAreas {
+		r.DrawWater(area)
+	}
+}
+
+func (l *Level) DrawCollisionables(r *Renderer) {
+	for _, hitbox := range l.GroundHitboxes {
+		r.DrawHitbox(hitbox, rl.Orange)
+	}
+
+	for _, collisionable := range l.Collisionables {
+		if collisionable.Kind == collisions.SlopeCollider {
+			r.DrawSlope(collisionable, rl.Orange)
+		}
+	}
+
+	for _, prop := range l.Props {
+		if prop.Type == PropPlatform || prop.Type == PropLadder {
+			r.DrawHitbox(prop.HitboxRect, rl.Yellow)
+		}
+	}
+
+	for _, hitbox := range l.OneWayHitboxes {
+		r.DrawHitbox(hitbox, rl.Yellow)
+	}
+
+	for _, hitbox := range l.HazardHitboxes {
+		r.DrawHitbox(hitbox, rl.Red)
+	}
+}
+
 func (l *Level) DrawParticles(r *Renderer) {
 	for _, particle := range l.Particles {
 		r.DrawParticle(particle)
@@ -92,6 +203,49 @@ func (l *Level) GetLayer(layerName string) *LevelLayer {
 	return nil
 }
 
+// GetCollisionLayer returns the Collision layer as long as something is painted on it. LDtk
+// adds the layer to every level, so an empty one means the level still collides with its
+// Ground tiles.
+func (l *Level) GetCollisionLayer() *LevelLayer {
+	layer := l.GetLayer("Collision")
+	if layer == nil {
+		return nil
+	}
+
+	for _, value := range layer.IntGrid {
+		if IntGridValue(value) != EmptyCell {
+			return layer
+		}
+	}
+
+	return nil
+}
+
+// GetCellAt returns the value painted on the cell holding the point, outside of the layer
+// nothing is.
+func (ll *LevelLayer) GetCellAt(x float32, y float32) IntGridValue {
+	column, row := int(math.Floor(float64(x/TileSize))), int(math.Floor(float64(y/TileSize)))
+	if column < 0 || row < 0 || column >= ll.Columns || row >= ll.Rows {
+		return EmptyCell
+	}
+
+	return IntGridValue(ll.IntGrid[row*ll.Columns+column])
+}
+
+func (ll *LevelLayer) GetCells(value IntGridValue) []rl.Rectangle {
+	var cells []rl.Rectangle
+	for i, cell := range ll.IntGrid {
+		if IntGridValue(cell) != value {
+			continue
+		}
+
+		x, y := i%ll.Columns, i/ll.Columns
+		cells = append(cells, rl.NewRectangle(float32(x)*TileSize, float32(y)*TileSize, TileSize, TileSize))
+	}
+
+	return cells
+}
+
 func (l *Level) GetEntitiesLayer() *LevelLayer {
 	for _, layer := range l.Layers {
 		if layer.Name == "Entities" {
@@ -103,16 +257,21 @@ func (l *Level) GetEntitiesLayer() *LevelLayer {
 }
 
 func (l *Level) LoadParticles() {
+	l.Particles = nil
+
 	// TODO: the particles density should be determined by a level prop
-	for range 20 {
-		l.Particles = append(l.Particles, NewParticle())
+	screens := float32(l.Width*l.Height) / (VIEWPORT_WIDTH * VIEWPORT_HEIGHT)
+	for range int(float32(AMBIENT_PARTICLES_PER_SCREEN) * screens) {
+		l.Particles = append(l.Particles, NewParticle(l.Random, l.Width, l.Height))
 	}
 }
 
 func (ll *LevelLayer) LoadLayout() {
+	tileset := strings.TrimSuffix(filepath.Base(ll.TilesetPath), filepath.Ext(ll.TilesetPath))
+
 	var tiles []*Tile
 	for _, rawTile := range ll.RawLayout {
-		tiles = append(tiles, NewTileFromLDtk(rawTile))
+		tiles = append(tiles, NewTileFromLDtk(rawTile, tileset))
 	}
 
 	ll.Layout = tiles
@@ -133,20 +292,155 @@ func (l *Level) LoadProps() {
 }
 
 func (l *Level) LoadCollisionables() {
-	var collisionableHitboxes []*rl.Rectangle
+	var collisionables []*collisions.Collider
+	collisionLayer := l.GetCollisionLayer()
 
-	groundLayer := l.GetLayer("Ground")
-	for _, tile := range groundLayer.Layout {
-		collisionableHitboxes = append(collisionableHitboxes, &tile.HitboxRect)
+	var groundTiles []rl.Rectangle
+	l.OneWayHitboxes = nil
+	l.HazardHitboxes = nil
+	l.WaterAreas = nil
+
+	// once collision is painted the Ground tiles are only decoration, except for slopes since
+	// the grid has no value for them. A solid cell painted over a slope still wins.
+	for _, tile := range l.GetLayer("Ground").Layout {
+		if shape, isSlope := tile.GetSlopeShape(); isSlope {
+			if collisionLayer == nil || collisionLayer.GetCellAt(tile.HitboxRect.X, tile.HitboxRect.Y) != SolidCell {
+				collisionables = append(collisionables, collisions.NewSlopeCollider(&tile.HitboxRect, shape.LeftHeight, shape.RightHeight))
+			}
+
+			continue
+		}
+
+		if collisionLayer == nil {
+			groundTiles = append(groundTiles, tile.HitboxRect)
+		}
+	}
+
+	if collisionLayer != nil {
+		groundTiles = collisionLayer.GetCells(SolidCell)
+		l.OneWayHitboxes = collisions.MergeGridRectangles(collisionLayer.GetCells(OneWayCell), TileSize)
+		l.HazardHitboxes = collisions.MergeGridRectangles(collisionLayer.GetCells(HazardCell), TileSize)
+		l.WaterAreas = collisions.MergeGridRectangles(collisionLayer.GetCells(WaterCell), TileSize)
+	}
+
+	l.GroundHitboxes = collisions.MergeGridRectangles(groundTiles, TileSize)
+	for i := range l.GroundHitboxes {
+		collisionables = append(collisionables, collisions.NewCollider(&l.GroundHitboxes[i], collisions.SolidCollider))
+	}
+
+	for i := range l.OneWayHitboxes {
+		collisionables = append(collisionables, collisions.NewCollider(&l.OneWayHitboxes[i], collisions.OneWayCollider))
 	}
 
 	for _, prop := range l.Props {
+		// the top of a ladder can be stood on like a platform
+		if prop.Type == PropPlatform || prop.Type == PropLadder {
+			collisionables = append(collisionables, collisions.NewCollider(&prop.HitboxRect, collisions.OneWayCollider))
+			continue
+		}
+
+		if prop.Type == PropWater {
+			l.WaterAreas = append(l.WaterAreas, prop.HitboxRect)
+			continue
+		}
+
 		if prop.Walkable {
 			continue
 		}
 
-		collisionableHitboxes = append(collisionableHitboxes, &prop.HitboxRect)
+		collisionables = append(collisionables, collisions.NewCollider(&prop.HitboxRect, collisions.SolidCollider))
+	}
+
+	l.Collisionables = collisionables
+
+	l.CollisionGrid = collisions.NewSpatialGrid(collisions.GRID_CELL_SIZE)
+	for _, collisionable := range l.Collisionables {
+		l.CollisionGrid.Insert(collisionable)
+	}
+}
+
+func (l *Level) AddCollisionable(hitbox *rl.Rectangle, kind collisions.ColliderKind) {
+	if l.FindCollisionable(hitbox) != nil {
+		return
+	}
+
+	collisionable := collisions.NewCollider(hitbox, kind)
+	l.Collisionables = append(l.Collisionables, collisionable)
+	l.CollisionGrid.Insert(collisionable)
+}
+
+func (l *Level) RemoveCollisionable(hitbox *rl.Rectangle) {
+	collisionable := l.FindCollisionable(hitbox)
+	if collisionable == nil {
+		return
+	}
+
+	l.Collisionables = slices.DeleteFunc(l.Collisionables, func(candidate *collisions.Collider) bool {
+		return candidate == collisionable
+	})
+	l.CollisionGrid.Remove(collisionable)
+}
+
+func (l *Level) FindCollisionable(hitbox *rl.Rectangle) *collisions.Collider {
+	for _, collisionable := range l.Collisionables {
+		if collisionable.Rect == hitbox {
+			return collisionable
+		}
+	}
+
+	return nil
+}
+
+// FindLadder returns the ladder overlapping the area, as long as its middle is in front of it.
+func (l *Level) FindLadder(area rl.Rectangle) *Prop {
+	middle := area.X + area.Width/2
+
+	for _, prop := range l.Props {
+		if prop.Type != PropLadder {
+			continue
+		}
+
+		if middle < prop.HitboxRect.X || middle > prop.HitboxRect.X+prop.HitboxRect.Width {
+			continue
+		}
+
+		if rl.CheckCollisionRecs(area, prop.HitboxRect) {
+			return prop
+		}
+	}
+
+	return nil
+}
+
+// GetSubmersion tells how much of the area is under water, from 0 to 1, along with the
+// surface of the deepest water it is in.
+func (l *Level) GetSubmersion(area rl.Rectangle) (float32, float32) {
+	var submersion float32
+	var surface float32
+
+	for _, water := range l.WaterAreas {
+		if !rl.CheckCollisionRecs(area, water) {
+			continue
+		}
+
+		overlap := rl.GetCollisionRec(area, water)
+		if depth := overlap.Height / area.Height; depth > submersion {
+			submersion = depth
+			surface = water.Y
+		}
+	}
+
+	return submersion, surface
+}
+
+// SpawnSplash throws a handful of droplets up from the water surface.
+func (l *Level) SpawnSplash(position rl.Vector2) {
+	for range SPLASH_PARTICLES {
+		l.Particles = append(l.Particles, NewSplashParticle(position, l.Random))
 	}
+}
 
-	l.CollisionableHitboxes = collisionableHitboxes
+// QueryCollisionables returns the collisionables overlapping or touching the area.
+func (l *Level) QueryCollisionables(area rl.Rectangle) []*collisions.Collider {
+	return l.CollisionGrid.Query(area)
 }
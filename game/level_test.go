@@ -0,0 +1,120 @@
+package game
+
+import (
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"game3/collisions"
+	"game3/input"
+)
+
+// paintCells fills a block of cells of the Collision layer, like a brush would in LDtk.
+func paintCells(level *Level, column int, row int, width int, height int, value IntGridValue) {
+	layer := level.GetLayer("Collision")
+	for y := row; y < row+height; y++ {
+		for x := column; x < column+width; x++ {
+			layer.IntGrid[y*layer.Columns+x] = int(value)
+		}
+	}
+}
+
+func solidCollidersAt(level *Level, area rl.Rectangle) int {
+	var solid int
+	for _, collisionable := range level.QueryCollisionables(area) {
+		if collisionable.Kind == collisions.SolidCollider && rl.CheckCollisionRecs(*collisionable.Rect, area) {
+			solid++
+		}
+	}
+
+	return solid
+}
+
+func TestPaintedCollisionReplacesGroundTiles(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	air := rl.NewRectangle(20*TileSize, 5*TileSize, TileSize, TileSize)
+	wall := rl.NewRectangle(0, 0, TileSize, TileSize)
+
+	if solidCollidersAt(g.CurrentLevel, wall) == 0 || solidCollidersAt(g.CurrentLevel, air) != 0 {
+		t.Fatalf("expected an empty Collision layer to leave the Ground tiles solid")
+	}
+
+	paintCells(g.World.FindLevel("Level_4"), 20, 5, 1, 1, SolidCell)
+	g.mustLoadLevel("Level_4")
+
+	if solidCollidersAt(g.CurrentLevel, air) != 1 {
+		t.Fatalf("expected the painted cell to be solid")
+	}
+
+	if solidCollidersAt(g.CurrentLevel, wall) != 0 {
+		t.Fatalf("expected the Ground tiles to be decoration once collision is painted")
+	}
+}
+
+func TestPaintedHazardsAndWater(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	level := g.World.FindLevel("Level_4")
+	paintCells(level, 4, 2, 10, 4, HazardCell)
+	paintCells(level, 20, 5, 10, 6, WaterCell)
+	g.mustLoadLevel("Level_4")
+
+	submersion, surface := g.CurrentLevel.GetSubmersion(rl.NewRectangle(24*TileSize, 7*TileSize, TileSize, TileSize))
+	if submersion != 1 || surface != 5*TileSize {
+		t.Fatalf("expected to be under the painted water, got %f under a surface at %f", submersion, surface)
+	}
+
+	g.Player.Teleport(rl.NewVector2(6*TileSize, 3*TileSize))
+	stepFrames(g, 1, input.State{})
+
+	if g.Player.Health >= g.Player.MaxHealth {
+		t.Fatalf("expected the painted hazard to hurt the player")
+	}
+}
+
+func slopeColliders(level *Level) int {
+	var slopes int
+	for _, collisionable := range level.Collisionables {
+		if collisionable.Kind == collisions.SlopeCollider {
+			slopes++
+		}
+	}
+
+	return slopes
+}
+
+func TestPaintedLevelKeepsItsSlopes(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	level := g.World.FindLevel("Level_3")
+	slopes := func() int {
+		g.mustLoadLevel("Level_3")
+		return slopeColliders(g.CurrentLevel)
+	}
+
+	unpainted := slopes()
+	if unpainted == 0 {
+		t.Fatalf("expected Level_3 to have Ground slopes")
+	}
+
+	paintCells(level, 0, 0, 1, 1, SolidCell)
+	if painted := slopes(); painted != unpainted {
+		t.Fatalf("expected the Ground slopes to keep colliding once collision is painted, got %d of %d", painted, unpainted)
+	}
+
+	// the 45° slope rising from 192,160, its middle is half a tile up
+	player := g.Player
+	player.Teleport(rl.NewVector2(192, 100))
+	tickUntilGrounded(t, player, g.CurrentLevel, input.State{})
+	if feet := player.Position.Y + player.HitboxRect.Height; feet < 160 || feet > 166 {
+		t.Fatalf("expected to stand on the slope, feet at %f", feet)
+	}
+
+	// a solid cell painted over a slope takes its place
+	paintCells(level, 24, 20, 1, 1, SolidCell)
+	if painted := slopes(); painted != unpainted-1 {
+		t.Fatalf("expected the painted cell to replace one slope, got %d of %d", painted, unpainted)
+	}
+
+	if solidCollidersAt(g.CurrentLevel, rl.NewRectangle(192, 160, TileSize, TileSize)) != 1 {
+		t.Fatalf("expected the painted cell over the slope to be solid")
+	}
+}
@@ -7,6 +7,19 @@ import (
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+type ParticleType int
+
+const (
+	AmbientParticle ParticleType = iota
+	SplashParticle
+)
+
+const (
+	SPLASH_PARTICLES             int     = 8
+	SPLASH_GRAVITY               float32 = 300
+	AMBIENT_PARTICLES_PER_SCREEN int     = 20
+)
+
 type ParticleSource struct {
 	ThermalStrength float32
 	Turbulence      float32
@@ -18,13 +31,14 @@ type Particle struct {
 	Velocity     rl.Vector2
 	FramesToLive int
 	Seed         float32
-	Type         string // TODO: dust, rain, fire...
+	Type         ParticleType // TODO: dust, rain, fire...
 	Source       *ParticleSource
 }
 
-func NewParticle() *Particle {
-	positionX := rand.Intn(320)
-	positionY := rand.Intn(180)
+// NewParticle places an ambient particle anywhere in a level of the given size.
+func NewParticle(random *rand.Rand, width int, height int) *Particle {
+	positionX := random.Intn(width)
+	positionY := random.Intn(height)
 
 	source := &ParticleSource{
 		ThermalStrength: 15,
@@ -35,19 +49,39 @@ func NewParticle() *Particle {
 	return &Particle{
 		Position:     rl.NewVector2(float32(positionX), float32(positionY)),
 		Velocity:     rl.NewVector2(0, 0),
-		FramesToLive: 600 + rand.Intn(600),
-		Seed:         float32(rl.GetRandomValue(0, 1000)) * 0.01,
+		FramesToLive: 10*int(SIMULATION_FPS) + random.Intn(10*int(SIMULATION_FPS)),
+		Seed:         float32(random.Intn(1001)) * 0.01,
 		Source:       source,
 	}
 }
 
+func NewSplashParticle(position rl.Vector2, random *rand.Rand) *Particle {
+	velocityX := float32(random.Intn(81) - 40)
+	velocityY := -float32(40 + random.Intn(60))
+
+	return &Particle{
+		Position:     rl.NewVector2(position.X+float32(random.Intn(9)-4), position.Y),
+		Velocity:     rl.NewVector2(velocityX, velocityY),
+		FramesToLive: int(SIMULATION_FPS)/3 + random.Intn(int(SIMULATION_FPS)/3),
+		Type:         SplashParticle,
+	}
+}
+
 // TODO: no particles seem to be moving leftwards...
 // TODO: I should have several methods for each particle type...
-func (p *Particle) UpdatePosition(delta float32) {
+func (p *Particle) UpdatePosition(delta float32, random *rand.Rand) {
+	if p.Type == SplashParticle {
+		p.Velocity.Y += SPLASH_GRAVITY * delta
+		p.Position = rl.Vector2Add(p.Position, rl.Vector2Scale(p.Velocity, delta))
+		p.FramesToLive--
+
+		return
+	}
+
 	thermalStrength := float32(15.0)
 	turbulence := float32(8.0)
 	drift := float32(5.0)
-	driftDirection := rand.Intn(2)
+	driftDirection := random.Intn(2)
 
 	posX := p.Position.X * 0.01
 	posY := p.Position.Y * 0.01
@@ -64,7 +98,8 @@ func (p *Particle) UpdatePosition(delta float32) {
 	totalForceX := horizontalDrift + turbulenceX
 	totalForceY := thermal + turbulenceY
 
-	resistance := float32(0.92)
+	// 0.92 was tuned per 60 fps frame
+	resistance := float32(math.Pow(0.92, float64(delta*60)))
 	p.Velocity.X = p.Velocity.X*resistance + totalForceX*delta
 	p.Velocity.Y = p.Velocity.Y*resistance + totalForceY*delta
 
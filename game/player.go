@@ -2,11 +2,13 @@ package game
 
 import (
 	"math"
+	"slices"
+	"sort"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 
-	"game3/assets"
 	"game3/collisions"
+	"game3/input"
 )
 
 const (
@@ -16,6 +18,56 @@ const (
 	PLAYER_ACCELERATION float32 = 500
 	PLAYER_DECELERATION float32 = 700
 	PLAYER_JUMP_FORCE   float32 = -200
+
+	// frames during which one way platforms are ignored after dropping through them
+	PLAYER_DROP_THROUGH_FRAMES int32 = SIMULATION_FPS / 5
+
+	// frames after walking off a ledge during which the player can still jump
+	PLAYER_COYOTE_FRAMES int32 = SIMULATION_FPS / 10
+	// frames a jump pressed before landing is remembered for
+	PLAYER_JUMP_BUFFER_FRAMES int32 = SIMULATION_FPS / 10
+
+	// releasing jump while going up multiplies the vertical velocity by this
+	PLAYER_JUMP_CUT_MULTIPLIER float32 = 0.4
+	// gravity is scaled down while jump is held and the vertical speed is under the threshold
+	PLAYER_APEX_THRESHOLD          float32 = 40
+	PLAYER_APEX_GRAVITY_MULTIPLIER float32 = 0.5
+	PLAYER_FALL_GRAVITY_MULTIPLIER float32 = 1.6
+
+	PLAYER_WALL_SLIDE_SPEED  float32 = 40
+	PLAYER_WALL_JUMP_FORCE_X float32 = 110
+	PLAYER_WALL_JUMP_FORCE_Y float32 = -190
+	// frames after a wall jump during which horizontal input is ignored
+	PLAYER_WALL_JUMP_LOCK_FRAMES int32 = SIMULATION_FPS / 8
+
+	// the dash ignores gravity and input for its whole duration, and can only be used once
+	// per jump
+	PLAYER_DASH_SPEED           float32 = 260
+	PLAYER_DASH_FRAMES          int32   = SIMULATION_FPS / 8
+	PLAYER_DASH_COOLDOWN_FRAMES int32   = SIMULATION_FPS / 3
+	// a trail sprite is left behind every this many frames while dashing
+	PLAYER_DASH_TRAIL_FRAMES int32 = SIMULATION_FPS / 40
+
+	PLAYER_MAX_HEALTH             int8    = 6
+	PLAYER_INVULNERABILITY_FRAMES int32   = SIMULATION_FPS
+	PLAYER_KNOCKBACK_FORCE_X      float32 = 120
+	PLAYER_KNOCKBACK_FORCE_Y      float32 = -150
+	PLAYER_KNOCKBACK_LOCK_FRAMES  int32   = SIMULATION_FPS / 5
+	// the sprite is hidden and shown every this many frames while invulnerable
+	PLAYER_FLICKER_FRAMES int32 = SIMULATION_FPS / 15
+
+	// gravity is off while climbing, the player moves along ladders at this speed
+	PLAYER_CLIMB_SPEED float32 = 60
+
+	// under water gravity is scaled down and pushed back by a buoyancy that grows with how
+	// deep the player is, drag slows it down on both axes
+	PLAYER_WATER_GRAVITY_MULTIPLIER float32 = 0.25
+	PLAYER_WATER_BUOYANCY           float32 = 230
+	PLAYER_WATER_DRAG               float32 = 4
+	PLAYER_SWIM_STROKE_FORCE        float32 = -110
+	PLAYER_SWIM_SPEED_MULTIPLIER    float32 = 0.6
+	// part of the player that has to be under water for it to swim
+	PLAYER_SWIM_DEPTH float32 = 0.5
 )
 
 type FacingDirection int
@@ -30,71 +82,74 @@ type PlayerAction int
 const (
 	None PlayerAction = iota
 	Jump
+	WallJump
+	Dash
+	Hurt
 	PickupProp
 )
 
 type Player struct {
-	Position          rl.Vector2
-	Velocity          rl.Vector2
-	MaxHealth         int8
-	Health            int8
-	Sprite            rl.Texture2D
-	TextureRect       rl.Rectangle
-	HitboxRect        rl.Rectangle
-	InteractiveRect   rl.Rectangle
-	CurrentFrame      int32
-	FramesCounter     int32
-	FramesSpeed       int32
-	FacingDirection   FacingDirection
-	State             string
-	LastStateChangeAt float64
-	OnGround          bool
-	IsRunning         bool
-	IsJumping         bool
-	IsFalling         bool
-	IsInteracting     bool
-	IsDead            bool
-	CanJump           bool
-	WentNorth         bool
-	WentWest          bool
-	WentSouth         bool
-	WentEast          bool
-	Inventory         []*Prop
-	ActivePropIndex   int
-	Path              []rl.Vector2
-	LastAction        PlayerAction
-	CollisionSystem   CollisionSystem
+	Position             rl.Vector2
+	PreviousPosition     rl.Vector2
+	Velocity             rl.Vector2
+	MaxHealth            int8
+	Health               int8
+	HitboxRect           rl.Rectangle
+	InteractiveRect      rl.Rectangle
+	Animator             *Animator
+	FacingDirection      FacingDirection
+	State                PlayerState
+	StateFrames          int32
+	TransitionListeners  []PlayerTransitionListener
+	OnGround             bool
+	Contacts             collisions.Contacts
+	IsWallSliding        bool
+	InputLockTimer       int32
+	InvulnerabilityTimer int32
+	DashTimer            int32
+	DashCooldownTimer    int32
+	DashAvailable        bool
+	IsInteracting        bool
+	CoyoteTimer          int32
+	JumpBufferTimer      int32
+	JumpHeld             bool
+	JumpCuttable         bool
+	DropThroughTimer     int32
+	Submersion           float32
+	WaterSurface         float32
+	WentNorth            bool
+	WentWest             bool
+	WentSouth            bool
+	WentEast             bool
+	Inventory            []*Prop
+	ActivePropIndex      int
+	Path                 []rl.Vector2
+	LastAction           PlayerAction
+	CollisionSystem      CollisionSystem
+	Profile              MovementProfile
 }
 
 func InitPlayer(collisionSystem CollisionSystem) *Player {
-	playerImage := rl.LoadImageFromMemory(".png", assets.TILEMAP, int32(len(assets.TILEMAP)))
-
 	player := Player{
 		Position:        rl.NewVector2(10, 140),
 		Velocity:        rl.NewVector2(0, 0),
-		MaxHealth:       20,
-		Health:          20,
-		Sprite:          rl.LoadTextureFromImage(playerImage),
-		TextureRect:     rl.NewRectangle(0, 56, 8, 8),
-		CurrentFrame:    0,
-		FramesCounter:   0,
-		FramesSpeed:     2,
+		MaxHealth:       PLAYER_MAX_HEALTH,
+		Health:          PLAYER_MAX_HEALTH,
+		Animator:        NewAnimator(playerStateClips[Idle]),
 		FacingDirection: Right,
-		State:           "IDLE",
+		State:           Idle,
 		OnGround:        false,
-		CanJump:         true,
-		IsJumping:       false,
-		IsFalling:       false,
 		IsInteracting:   false,
-		IsDead:          false,
 		WentNorth:       false,
 		WentWest:        false,
 		WentSouth:       false,
 		WentEast:        false,
 		LastAction:      None,
 		CollisionSystem: collisionSystem,
+		Profile:         DefaultMovementProfile(),
 	}
 
+	player.PreviousPosition = player.Position
 	player.HitboxRect = rl.NewRectangle(player.Position.X, player.Position.Y, 8, 8)
 
 	interactiveRect := rl.Rectangle{
@@ -108,29 +163,37 @@ func InitPlayer(collisionSystem CollisionSystem) *Player {
 	return &player
 }
 
-func (player *Player) Draw(r *Renderer) {
-	var spriteVector rl.Vector2
-
-	if player.FacingDirection == Right {
-		spriteVector = player.Position
-		player.TextureRect.Width = 8
-	}
+func (player *Player) Draw(r *Renderer, alpha float32) {
+	spriteVector := rl.Vector2Lerp(player.PreviousPosition, player.Position, alpha)
+	rec := player.Animator.SourceRect()
 
 	if player.FacingDirection == Left {
-		player.TextureRect.Width = -8
+		rec.Width = -rec.Width
 
 		// weird hack, the recommended way to flip a texture in raylib, negating the width, offsets it...
-		spriteVector = rl.NewVector2(player.Position.X-1, player.Position.Y)
+		spriteVector.X -= 1
+	}
+
+	player.DrawInventory(r, alpha)
+
+	if player.IsVisible() {
+		rl.DrawTextureRec(r.Textures[player.Animator.Texture()], rec, spriteVector, rl.White)
 	}
+}
 
-	player.DrawInventory(r)
-	rl.DrawTextureRec(player.Sprite, player.TextureRect, spriteVector, rl.White)
+func (player *Player) DrawInventory(r *Renderer, alpha float32) {
+	for _, prop := range player.Inventory {
+		r.DrawPropAt(prop, rl.Vector2Lerp(prop.PreviousPosition, prop.Position, alpha))
+	}
 }
 
-func (player *Player) DrawInventory(r *Renderer) {
+// UpdateInventory makes the carried props follow the path walked by the player.
+func (player *Player) UpdateInventory(delta float32) {
 	isMoving := rl.Vector2Length(player.Velocity) > 0.1
 
 	for i := range player.Inventory {
+		player.Inventory[i].Animator.Update(delta)
+
 		var targetDistance int
 		if isMoving {
 			targetDistance = 15 * (i + 1)
@@ -161,14 +224,14 @@ func (player *Player) DrawInventory(r *Renderer) {
 			}
 		}
 
-		smoothing := float32(0.15)
+		// 0.15 was tuned per 60 fps frame
+		smoothing := 1 - float32(math.Pow(0.85, float64(delta*60)))
+		player.Inventory[i].PreviousPosition = player.Inventory[i].Position
 		player.Inventory[i].Position = rl.Vector2Lerp(
 			player.Inventory[i].Position,
 			targetPos,
 			smoothing,
 		)
-
-		r.DrawProp(player.Inventory[i])
 	}
 }
 
@@ -178,14 +241,20 @@ func (player *Player) DrawHitbox() {
 	rl.DrawPixel(int32(player.Position.X), int32(player.Position.Y), rl.Green)
 }
 
-func (player *Player) Tick(delta float32, level *Level, activeGamepad int32) {
+func (player *Player) Tick(delta float32, level *Level, actions input.State) {
 	player.LastAction = None
+	player.PreviousPosition = player.Position
 
 	player.UpdatePosition(delta, level)
 	player.UpdateState()
-	player.UpdateAnimation()
+	player.Animator.Update(delta)
 	player.RecordPath()
-	player.CheckDeath(level)
+	player.UpdateInventory(delta)
+	player.CheckHazards(level)
+
+	if player.State == Dead {
+		return
+	}
 
 	if player.IsInteracting {
 		player.PickupCollidingProps(level)
@@ -194,30 +263,57 @@ func (player *Player) Tick(delta float32, level *Level, activeGamepad int32) {
 		player.IsInteracting = false
 	}
 
-	player.ProcessInput(delta, activeGamepad)
+	if player.UpdateClimbing(level, actions) {
+		return
+	}
+
+	player.ProcessInput(delta, actions)
 }
 
-func (player *Player) ProcessInput(delta float32, activeGamepad int32) {
-	moveLeft := rl.IsKeyDown(rl.KeyA) || rl.IsGamepadButtonDown(activeGamepad, rl.GamepadButtonLeftFaceLeft)
-	moveRight := rl.IsKeyDown(rl.KeyD) || rl.IsGamepadButtonDown(activeGamepad, rl.GamepadButtonLeftFaceRight)
-	jump := rl.IsKeyDown(rl.KeySpace) || rl.IsGamepadButtonDown(activeGamepad, rl.GamepadButtonRightFaceDown)
+func (player *Player) ProcessInput(delta float32, actions input.State) {
+	moveLeft := actions.IsHeld(input.MoveLeft)
+	moveRight := actions.IsHeld(input.MoveRight)
+	moveDown := actions.IsHeld(input.MoveDown)
+	jumpPressed := actions.IsPressed(input.Jump)
+	player.JumpHeld = actions.IsHeld(input.Jump)
+	isInteracting := actions.IsReleased(input.Interact)
+
+	if jumpPressed {
+		player.JumpBufferTimer = player.Profile.JumpBufferFrames
+	}
+
+	if player.OnGround {
+		player.DashAvailable = true
+	}
 
-	jumpReleased := rl.IsKeyReleased(rl.KeySpace) || rl.IsGamepadButtonReleased(activeGamepad, rl.GamepadButtonRightFaceDown)
-	isInteracting := rl.IsKeyReleased(rl.KeyE) || rl.IsGamepadButtonReleased(activeGamepad, rl.GamepadButtonRightFaceLeft)
+	if actions.IsPressed(input.Dash) && player.CanDash() {
+		player.StartDash(actions)
+	}
 
-	// prevents spamming jumps
-	if jumpReleased {
-		player.CanJump = true
+	// right after a wall jump or a hit the player can not steer back
+	isLocked := player.InputLockTimer > 0
+	if isLocked {
+		player.InputLockTimer--
+		moveLeft, moveRight = false, false
 	}
 
+	if player.IsDashing() {
+		moveLeft, moveRight = false, false
+	}
+
+	player.IsWallSliding = !player.OnGround && !player.IsSwimming() && player.Velocity.Y >= 0 &&
+		((player.Contacts.LeftWall && moveLeft) || (player.Contacts.RightWall && moveRight))
+
+	moveSpeed := player.GetMoveSpeed()
+
 	if moveLeft && !moveRight {
 		player.FacingDirection = Left
 
-		if player.Velocity.X > -PLAYER_MOVE_SPEED {
-			player.Velocity.X -= PLAYER_ACCELERATION * delta
+		if player.Velocity.X > -moveSpeed {
+			player.Velocity.X -= player.Profile.Acceleration * delta
 
-			if player.Velocity.X < -PLAYER_MOVE_SPEED {
-				player.Velocity.X = -PLAYER_MOVE_SPEED
+			if player.Velocity.X < -moveSpeed {
+				player.Velocity.X = -moveSpeed
 			}
 		}
 	}
@@ -225,24 +321,24 @@ func (player *Player) ProcessInput(delta float32, activeGamepad int32) {
 	if moveRight && !moveLeft {
 		player.FacingDirection = Right
 
-		if player.Velocity.X < PLAYER_MOVE_SPEED {
-			player.Velocity.X += PLAYER_ACCELERATION * delta
+		if player.Velocity.X < moveSpeed {
+			player.Velocity.X += player.Profile.Acceleration * delta
 
-			if player.Velocity.X > PLAYER_MOVE_SPEED {
-				player.Velocity.X = PLAYER_MOVE_SPEED
+			if player.Velocity.X > moveSpeed {
+				player.Velocity.X = moveSpeed
 			}
 		}
 	}
 
-	if !moveLeft && !moveRight {
+	if !moveLeft && !moveRight && !isLocked && !player.IsDashing() {
 		if player.Velocity.X > 0 {
-			player.Velocity.X -= PLAYER_DECELERATION * delta
+			player.Velocity.X -= player.Profile.Deceleration * delta
 
 			if player.Velocity.X < 0 {
 				player.Velocity.X = 0
 			}
 		} else if player.Velocity.X < 0 {
-			player.Velocity.X += PLAYER_DECELERATION * delta
+			player.Velocity.X += player.Profile.Deceleration * delta
 
 			if player.Velocity.X > 0 {
 				player.Velocity.X = 0
@@ -250,156 +346,547 @@ func (player *Player) ProcessInput(delta float32, activeGamepad int32) {
 		}
 	}
 
-	if jump && player.OnGround && player.CanJump {
-		player.Velocity.Y = PLAYER_JUMP_FORCE
+	if jumpPressed && moveDown && player.Contacts.OnOneWay {
+		player.DropThroughTimer = player.Profile.DropThroughFrames
 		player.OnGround = false
-		player.CanJump = false
+		player.CoyoteTimer = 0
+		player.JumpBufferTimer = 0
+	}
+
+	if jumpPressed && player.IsSwimming() && !player.OnGround {
+		player.SwimStroke()
+	}
+
+	if player.JumpBufferTimer > 0 && (player.OnGround || player.CoyoteTimer > 0) {
+		player.Velocity.Y = player.Profile.JumpForce
+		player.OnGround = false
+		player.CoyoteTimer = 0
+		player.JumpBufferTimer = 0
+		player.JumpCuttable = true
 		player.LastAction = Jump
+		player.ChangeState(Jumping)
+	}
+
+	if player.JumpBufferTimer > 0 && !player.OnGround && (player.Contacts.LeftWall || player.Contacts.RightWall) {
+		player.WallJump()
+	}
+
+	if !player.JumpHeld && player.JumpCuttable {
+		if player.Velocity.Y < 0 {
+			player.Velocity.Y *= player.Profile.JumpCutMultiplier
+		}
+
+		player.JumpCuttable = false
 	}
 
 	if isInteracting {
 		player.IsInteracting = true
 	}
+
+	player.UpdateJumpTimers(jumpPressed)
 }
 
-func (player *Player) UpdatePosition(delta float32, level *Level) {
-	if !player.OnGround {
-		player.Velocity.Y += GRAVITY * delta
-		if player.Velocity.Y >= FALL_TERMINAL_VELOCITY {
-			player.Velocity.Y = FALL_TERMINAL_VELOCITY
-		}
+// UpdateJumpTimers counts simulation frames, not seconds, so both windows behave the same
+// when stepping through frames one by one. A jump is still possible during the profile's
+// CoyoteFrames after leaving the ground, and a press is remembered for its JumpBufferFrames.
+func (player *Player) UpdateJumpTimers(jumpPressed bool) {
+	if player.OnGround {
+		player.CoyoteTimer = player.Profile.CoyoteFrames
+	} else if player.CoyoteTimer > 0 {
+		player.CoyoteTimer--
 	}
 
-	if player.CollisionSystem == RayCastedCollision {
-		player.HandleRayCastedCollisions(level.CollisionableHitboxes, level, delta)
-	} else {
-		player.HandleRegularCollisions(level.CollisionableHitboxes, level, delta)
+	if !jumpPressed && player.JumpBufferTimer > 0 {
+		player.JumpBufferTimer--
 	}
+}
 
-	player.Position.X += player.Velocity.X * delta
-	player.Position.Y += player.Velocity.Y * delta
+// WallJump pushes the player up and away from the wall it is touching.
+func (player *Player) WallJump() {
+	player.Velocity.Y = player.Profile.WallJumpForceY
+	player.Velocity.X = player.Profile.WallJumpForceX
+	player.FacingDirection = Right
 
-	if player.Position.Y < -5 {
-		player.Position.Y = 180
-		player.WentNorth = true
+	if player.Contacts.RightWall {
+		player.Velocity.X = -player.Profile.WallJumpForceX
+		player.FacingDirection = Left
 	}
-	if player.Position.X > 320 {
-		player.Position.X = 0
-		player.WentEast = true
+
+	player.IsWallSliding = false
+	player.InputLockTimer = player.Profile.WallJumpLockFrames
+	player.JumpBufferTimer = 0
+	player.JumpCuttable = true
+	player.LastAction = WallJump
+	player.ChangeState(WallJumping)
+}
+
+func (player *Player) CanDash() bool {
+	return player.HasPropInInventory(PropDash) && player.DashAvailable && !player.IsDashing() && player.DashCooldownTimer == 0
+}
+
+func (player *Player) IsDashing() bool {
+	return player.DashTimer > 0
+}
+
+// StartDash launches the player towards the held directions, or straight ahead if none is.
+func (player *Player) StartDash(actions input.State) {
+	var direction rl.Vector2
+
+	if actions.IsHeld(input.MoveLeft) {
+		direction.X -= 1
 	}
-	if player.Position.Y > 185 {
-		player.Position.Y = 0
-		player.WentSouth = true
+	if actions.IsHeld(input.MoveRight) {
+		direction.X += 1
 	}
-	if player.Position.X < 0 {
-		player.Position.X = 320
-		player.WentWest = true
+	if actions.IsHeld(input.MoveUp) {
+		direction.Y -= 1
+	}
+	if actions.IsHeld(input.MoveDown) {
+		direction.Y += 1
 	}
 
-	player.UpdateHitbox()
+	if direction.X == 0 && direction.Y == 0 {
+		direction.X = 1
+		if player.FacingDirection == Left {
+			direction.X = -1
+		}
+	}
+
+	player.ChangeState(Dashing)
+	player.Velocity = rl.Vector2Scale(rl.Vector2Normalize(direction), player.Profile.DashSpeed)
+	player.DashTimer = player.Profile.DashFrames
+	player.DashCooldownTimer = player.Profile.DashCooldownFrames
+	player.DashAvailable = false
+	player.JumpCuttable = false
+	player.LastAction = Dash
 }
 
-func (player *Player) HandleRegularCollisions(collisionableElements []*rl.Rectangle, level *Level, delta float32) {
-	player.OnGround = false
+// UpdateDash counts the dash down, leaving the Dashing state ends it.
+func (player *Player) UpdateDash() {
+	if player.DashCooldownTimer > 0 {
+		player.DashCooldownTimer--
+	}
 
-	for _, collisionable := range collisionableElements {
-		hits, hitFace := collisions.CheckRectanglesCollision(player.HitboxRect, *collisionable)
+	if player.IsDashing() {
+		player.DashTimer--
+	}
+}
 
-		if hits {
-			rl.TraceLog(rl.LogInfo, "%d", hitFace)
+// SwimStroke pushes the player up through the water, it does not count as a jump.
+func (player *Player) SwimStroke() {
+	player.Velocity.Y = player.Profile.SwimStrokeForce
+	player.CoyoteTimer = 0
+	player.JumpBufferTimer = 0
+	player.JumpCuttable = false
+}
 
-			if hitFace == collisions.FaceRight {
-				player.Velocity.X = float32(math.Max(0, float64(player.Velocity.X)))
-			}
+// UpdateClimbing grabs, moves along and lets go of ladders. It tells whether the player is
+// still climbing, in which case the rest of the input is ignored.
+func (player *Player) UpdateClimbing(level *Level, actions input.State) bool {
+	moveUp := actions.IsHeld(input.MoveUp)
+	moveDown := actions.IsHeld(input.MoveDown)
+	bottom := player.HitboxRect.Y + player.HitboxRect.Height
 
-			if hitFace == collisions.FaceLeft {
-				player.Velocity.X = float32(math.Min(0, float64(player.Velocity.X)))
-			}
+	// the probe reaches one pixel under the feet so ladders can be grabbed from their top
+	probe := player.HitboxRect
+	probe.Height += 1
 
-			if hitFace == collisions.FaceTop {
-				player.Velocity.Y = float32(math.Min(0, float64(player.Velocity.Y)))
-				player.OnGround = true
-			}
+	if player.State != Climbing {
+		if moveUp == moveDown || player.IsDashing() || player.State == Knockback {
+			return false
+		}
 
-			if hitFace == collisions.FaceBottom {
-				player.Velocity.Y = float32(math.Max(0, float64(player.Velocity.Y)))
-			}
+		ladder := level.FindLadder(probe)
+		if ladder == nil {
+			return false
+		}
+
+		// there has to be some ladder left in the direction the player wants to go
+		if moveUp && ladder.HitboxRect.Y >= bottom {
+			return false
+		}
+		if moveDown && ladder.HitboxRect.Y+ladder.HitboxRect.Height <= bottom {
+			return false
 		}
+
+		player.StartClimbing(ladder)
+	}
+
+	if actions.IsPressed(input.Jump) {
+		player.JumpOffLadder(actions)
+		return true
+	}
+
+	// the ladder goes on in the level the player is moving into, it is looked up there next tick
+	if player.HasLeftLevel() {
+		return true
+	}
+
+	ladder := level.FindLadder(probe)
+	if ladder == nil {
+		player.ChangeState(Falling)
+		return false
+	}
+
+	player.Velocity = rl.NewVector2(0, 0)
+	if moveUp && !moveDown {
+		player.Velocity.Y = -player.Profile.ClimbSpeed
+	}
+	if moveDown && !moveUp {
+		player.Velocity.Y = player.Profile.ClimbSpeed
+	}
+
+	// climbing out at the top leaves the player standing on the ladder
+	if player.Velocity.Y < 0 && bottom <= ladder.HitboxRect.Y {
+		player.Position.Y = ladder.HitboxRect.Y - player.HitboxRect.Height
+		player.Velocity.Y = 0
+		player.UpdateHitbox()
+		player.ChangeState(Idle)
+
+		return false
+	}
+
+	// the top of the ladder itself does not count, that is where climbing down starts
+	if moveDown && player.OnGround && !player.Contacts.OnOneWay {
+		player.Velocity.Y = 0
+		player.ChangeState(Idle)
+
+		return false
+	}
+
+	return true
+}
+
+// StartClimbing centers the player on the ladder and stops it in place.
+func (player *Player) StartClimbing(ladder *Prop) {
+	player.Position.X = ladder.HitboxRect.X + (ladder.HitboxRect.Width-player.HitboxRect.Width)/2
+	player.Velocity = rl.NewVector2(0, 0)
+	player.UpdateHitbox()
+
+	player.JumpBufferTimer = 0
+	player.CoyoteTimer = 0
+	player.JumpCuttable = false
+	player.DashAvailable = true
+
+	player.ChangeState(Climbing)
+}
+
+// JumpOffLadder jumps away in the held direction, or just lets go when down is held.
+func (player *Player) JumpOffLadder(actions input.State) {
+	var direction float32
+	if actions.IsHeld(input.MoveLeft) {
+		direction--
+	}
+	if actions.IsHeld(input.MoveRight) {
+		direction++
+	}
+
+	if actions.IsHeld(input.MoveDown) {
+		player.Velocity = rl.NewVector2(0, 0)
+		player.ChangeState(Falling)
+
+		return
 	}
+
+	player.Velocity = rl.NewVector2(direction*player.Profile.MoveSpeed, player.Profile.JumpForce)
+	player.JumpCuttable = true
+	player.LastAction = Jump
+	player.ChangeState(Jumping)
 }
 
-func (player *Player) HandleRayCastedCollisions(collisionableElements []*rl.Rectangle, level *Level, delta float32) {
-	
+// GetWallJumpVFXPosition places the puff against the wall the player just left.
+func (player *Player) GetWallJumpVFXPosition() rl.Vector2 {
+	if player.FacingDirection == Right {
+		return rl.NewVector2(player.Position.X-4, player.Position.Y)
+	}
+
+	return rl.NewVector2(player.Position.X+4, player.Position.Y)
 }
 
-func (player *Player) UpdateState() {
-	var isRunning bool
-	var isJumping bool
-	var isFalling bool
+func (player *Player) UpdateSubmersion(level *Level) {
+	submersion, surface := level.GetSubmersion(player.HitboxRect)
 
-	if player.Velocity.X != 0 {
-		isRunning = true
-		player.FramesSpeed = 6
+	player.Submersion = submersion
+	if submersion > 0 {
+		player.WaterSurface = surface
 	}
+}
+
+func (player *Player) IsSwimming() bool {
+	return player.Submersion >= PLAYER_SWIM_DEPTH
+}
 
-	if player.Velocity.X == 0 {
-		isRunning = false
-		player.FramesSpeed = 2
+// ApplyWaterForces replaces gravity while in water. Buoyancy is only fought on the ground,
+// so the player can stand in shallow water but floats off the bottom of deep water.
+func (player *Player) ApplyWaterForces(delta float32) {
+	acceleration := player.Profile.Gravity*player.Profile.WaterGravityMultiplier - player.Profile.WaterBuoyancy*player.Submersion
+	if !player.OnGround || acceleration < 0 {
+		player.Velocity.Y += acceleration * delta
 	}
 
-	if player.Velocity.Y < 0 {
-		isJumping = true
+	drag := float32(math.Exp(float64(-player.Profile.WaterDrag * delta)))
+	player.Velocity = rl.Vector2Scale(player.Velocity, drag)
+}
+
+// GetMoveSpeed is the top horizontal speed, lower while swimming.
+func (player *Player) GetMoveSpeed() float32 {
+	if player.IsSwimming() {
+		return player.Profile.MoveSpeed * player.Profile.SwimSpeedMultiplier
+	}
+
+	return player.Profile.MoveSpeed
+}
+
+// GetGravityMultiplier floats the player a little at the top of a held jump and makes
+// it fall faster than it goes up.
+func (player *Player) GetGravityMultiplier() float32 {
+	if player.JumpHeld && float32(math.Abs(float64(player.Velocity.Y))) < player.Profile.ApexThreshold {
+		return player.Profile.ApexGravityMultiplier
 	}
 
 	if player.Velocity.Y > 0 {
-		isFalling = true
+		return player.Profile.FallGravityMultiplier
 	}
 
-	player.IsRunning = isRunning
-	player.IsJumping = isJumping
-	player.IsFalling = isFalling
+	return 1
 }
 
-func (player *Player) UpdateHitbox() {
-	player.HitboxRect.X = player.Position.X
-	player.HitboxRect.Y = player.Position.Y
+func (player *Player) UpdatePosition(delta float32, level *Level) {
+	player.UpdateDash()
+	player.UpdateSubmersion(level)
 
-	player.InteractiveRect.X = player.Position.X - 2
-	player.InteractiveRect.Y = player.Position.Y - 2
+	freeFalling := player.State != Dashing && player.State != Climbing
+
+	if freeFalling && player.Submersion > 0 {
+		player.ApplyWaterForces(delta)
+	} else if freeFalling && !player.OnGround {
+		player.Velocity.Y += player.Profile.Gravity * player.GetGravityMultiplier() * delta
+		if player.Velocity.Y >= player.Profile.FallTerminalVelocity {
+			player.Velocity.Y = player.Profile.FallTerminalVelocity
+		}
+
+		if player.IsWallSliding && player.Velocity.Y > player.Profile.WallSlideSpeed {
+			player.Velocity.Y = player.Profile.WallSlideSpeed
+		}
+	}
+
+	nearbyCollisionables := level.QueryCollisionables(player.GetSweptHitbox(delta))
+
+	// climbing goes through the top of the ladders, they are one way colliders
+	ignoreOneWay := player.State == Climbing
+	if player.DropThroughTimer > 0 {
+		player.DropThroughTimer--
+		ignoreOneWay = true
+	}
+
+	if ignoreOneWay {
+		nearbyCollisionables = slices.DeleteFunc(nearbyCollisionables, func(collisionable *collisions.Collider) bool {
+			return collisionable.Kind == collisions.OneWayCollider
+		})
+	}
+
+	if player.CollisionSystem == RayCastedCollision {
+		player.HandleRayCastedCollisions(nearbyCollisionables, level, delta)
+	} else {
+		player.HandleRegularCollisions(nearbyCollisionables, level, delta)
+	}
+
+	player.UpdateHitbox()
+
+	// the player leaves once its middle crosses an edge, where it ends up is up to the game
+	center := player.GetCenter()
+	if center.Y < 0 {
+		player.WentNorth = true
+	}
+	if center.X > float32(level.Width) {
+		player.WentEast = true
+	}
+	if center.Y > float32(level.Height) {
+		player.WentSouth = true
+	}
+	if center.X < 0 {
+		player.WentWest = true
+	}
 }
 
-func (player *Player) UpdateAnimation() {
-	player.FramesCounter++
+func (player *Player) HasLeftLevel() bool {
+	return player.WentNorth || player.WentEast || player.WentSouth || player.WentWest
+}
 
-	if !player.IsRunning {
-		player.CurrentFrame = 0
-		player.TextureRect.X = 0
+func (player *Player) GetCenter() rl.Vector2 {
+	return rl.NewVector2(player.HitboxRect.X+player.HitboxRect.Width/2, player.HitboxRect.Y+player.HitboxRect.Height/2)
+}
 
-		return
+// KeepInside stops the player at the edges of a level with nothing on the other side.
+func (player *Player) KeepInside(level *Level) {
+	maxX := float32(level.Width) - player.HitboxRect.Width
+	maxY := float32(level.Height) - player.HitboxRect.Height
+
+	if player.Position.X < 0 || player.Position.X > maxX {
+		player.Position.X = rl.Clamp(player.Position.X, 0, maxX)
+		player.Velocity.X = 0
+	}
+
+	if player.Position.Y < 0 || player.Position.Y > maxY {
+		player.Position.Y = rl.Clamp(player.Position.Y, 0, maxY)
+		player.Velocity.Y = 0
+	}
+
+	player.UpdateHitbox()
+}
+
+// MoveBy shifts the player and everything it carries without interpolating, used to carry
+// them over into the space of another level.
+func (player *Player) MoveBy(offset rl.Vector2) {
+	player.Teleport(rl.Vector2Add(player.Position, offset))
+
+	for _, prop := range player.Inventory {
+		prop.Position = rl.Vector2Add(prop.Position, offset)
+		prop.PreviousPosition = prop.Position
+	}
+}
+
+func (player *Player) HandleRegularCollisions(collisionableElements []*collisions.Collider, level *Level, delta float32) {
+	displacement := rl.Vector2Scale(player.Velocity, delta)
+	stickToGround := player.OnGround && player.Velocity.Y >= 0
+	hitbox, _ := collisions.MoveAndResolve(player.HitboxRect, displacement, collisionableElements, stickToGround)
+
+	player.Position = rl.NewVector2(hitbox.X, hitbox.Y)
+	player.UpdateContacts(collisionableElements)
+}
+
+func (player *Player) HandleRayCastedCollisions(collisionableElements []*collisions.Collider, level *Level, delta float32) {
+	type contact struct {
+		collisionable *collisions.Collider
+		timeToHit     float32
 	}
 
-	if player.FramesCounter >= (60/player.FramesSpeed) && !player.IsJumping {
-		player.FramesCounter = 0
-		player.CurrentFrame++
+	dimensions := rl.NewVector2(player.HitboxRect.Width, player.HitboxRect.Height)
+
+	var contacts []contact
+	for _, collisionable := range collisionableElements {
+		timeToHit, hits, hitFace := collisions.CheckRay2DRectangleCollision(player.MovementRay(delta), *collisionable.Rect, dimensions)
 
-		if !player.IsRunning && player.CurrentFrame > 1 {
-			player.CurrentFrame = 0
+		// one way platforms can only be hit while falling onto them
+		if collisionable.Kind == collisions.OneWayCollider && hitFace != collisions.FaceTop {
+			continue
 		}
 
-		if player.IsRunning && player.CurrentFrame > 1 {
-			player.CurrentFrame = 0
+		// slopes are not boxes, they are resolved once the player has moved
+		if collisionable.Kind == collisions.SlopeCollider {
+			continue
 		}
 
-		if !player.IsRunning {
-			player.TextureRect.X = 0
+		if hits && timeToHit < 1 {
+			contacts = append(contacts, contact{collisionable, timeToHit})
 		}
+	}
 
-		if player.IsRunning {
-			offset := (float32(player.CurrentFrame) * 8) + 8
-			player.TextureRect.X = offset
+	// closest hits go first, resolving them can make the farther ones miss
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].timeToHit < contacts[j].timeToHit
+	})
+
+	for _, contact := range contacts {
+		timeToHit, hits, hitFace := collisions.CheckRay2DRectangleCollision(player.MovementRay(delta), *contact.collisionable.Rect, dimensions)
+		if !hits || timeToHit >= 1 {
+			continue
 		}
 
+		// the velocity is cut so the player ends the frame touching the face
+		if hitFace == collisions.FaceLeft || hitFace == collisions.FaceRight {
+			player.Velocity.X *= timeToHit
+		}
+
+		if hitFace == collisions.FaceTop || hitFace == collisions.FaceBottom {
+			player.Velocity.Y *= timeToHit
+		}
+	}
+
+	stickToGround := player.OnGround && player.Velocity.Y >= 0
+	displacement := rl.Vector2Scale(player.Velocity, delta)
+
+	hitbox := player.HitboxRect
+	hitbox.X += displacement.X
+	hitbox.Y += displacement.Y
+	hitbox = collisions.ResolveSlopes(hitbox, displacement.Y, collisionableElements)
+
+	if stickToGround {
+		hitbox = collisions.SnapToGround(hitbox, collisionableElements, float32(math.Abs(float64(displacement.X)))+collisions.CONTACT_TOLERANCE)
 	}
+
+	player.Position = rl.NewVector2(hitbox.X, hitbox.Y)
+	player.UpdateContacts(collisionableElements)
+}
+
+// UpdateContacts finds out which sides of the player are touching the level and stops
+// any movement going into them.
+func (player *Player) UpdateContacts(collisionableElements []*collisions.Collider) {
+	player.UpdateHitbox()
+	player.Contacts = collisions.GetContacts(player.HitboxRect, collisionableElements)
+	player.OnGround = player.Contacts.Ground
+
+	if player.Contacts.Ground {
+		player.Velocity.Y = float32(math.Min(0, float64(player.Velocity.Y)))
+	}
+
+	if player.Contacts.Ceiling {
+		player.Velocity.Y = float32(math.Max(0, float64(player.Velocity.Y)))
+	}
+
+	if player.Contacts.LeftWall {
+		player.Velocity.X = float32(math.Max(0, float64(player.Velocity.X)))
+	}
+
+	if player.Contacts.RightWall {
+		player.Velocity.X = float32(math.Min(0, float64(player.Velocity.X)))
+	}
+}
+
+// GetSweptHitbox covers the hitbox both where it is and where it will be after moving this
+// frame, grown by a pixel so surfaces the player is resting against are included too.
+// While on ground it also reaches as deep as the player can be snapped down a slope.
+func (player *Player) GetSweptHitbox(delta float32) rl.Rectangle {
+	displacement := rl.Vector2Scale(player.Velocity, delta)
+	horizontalDistance := float32(math.Abs(float64(displacement.X)))
+
+	var snapDistance float32
+	if player.OnGround {
+		snapDistance = horizontalDistance
+	}
+
+	return rl.NewRectangle(
+		player.HitboxRect.X+min(0, displacement.X)-1,
+		player.HitboxRect.Y+min(0, displacement.Y)-1,
+		player.HitboxRect.Width+horizontalDistance+2,
+		player.HitboxRect.Height+float32(math.Abs(float64(displacement.Y)))+snapDistance+2,
+	)
+}
+
+func (player *Player) MovementRay(delta float32) collisions.Ray2D {
+	return collisions.Ray2D{
+		Origin: rl.NewVector2(
+			player.HitboxRect.X+player.HitboxRect.Width/2,
+			player.HitboxRect.Y+player.HitboxRect.Height/2,
+		),
+		Direction: rl.Vector2Scale(player.Velocity, delta),
+	}
+}
+
+// Teleport moves the player without travelling, so nothing collides or gets interpolated
+// on the way.
+func (player *Player) Teleport(position rl.Vector2) {
+	player.Position = position
+	player.PreviousPosition = position
+	player.UpdateHitbox()
+}
+
+func (player *Player) UpdateHitbox() {
+	player.HitboxRect.X = player.Position.X
+	player.HitboxRect.Y = player.Position.Y
+
+	player.InteractiveRect.X = player.Position.X - 2
+	player.InteractiveRect.Y = player.Position.Y - 2
 }
 
 func (player *Player) PickupCollidingProps(level *Level) {
@@ -420,17 +907,74 @@ func (player *Player) PickupCollidingProps(level *Level) {
 	}
 }
 
-func (player *Player) CheckDeath(level *Level) {
-	for i := len(level.Props) - 1; i >= 0; i-- {
-		prop := level.Props[i]
-		if prop.Type != PropSpikes {
+func (player *Player) CheckHazards(level *Level) {
+	if player.InvulnerabilityTimer > 0 {
+		player.InvulnerabilityTimer--
+		return
+	}
+
+	for _, prop := range level.Props {
+		if prop.Damage <= 0 {
 			continue
 		}
 
 		if rl.CheckCollisionRecs(player.InteractiveRect, prop.HitboxRect) {
-			player.IsDead = true
+			player.TakeDamage(prop.Damage, prop.HitboxRect)
+			return
 		}
 	}
+
+	// painted hazards hurt like spikes do
+	for _, hazard := range level.HazardHitboxes {
+		if rl.CheckCollisionRecs(player.InteractiveRect, hazard) {
+			player.TakeDamage(SPIKES_DAMAGE, hazard)
+			return
+		}
+	}
+}
+
+// TakeDamage knocks the player away from whatever hurt it and leaves it invulnerable for
+// a while, or kills it once it runs out of health.
+func (player *Player) TakeDamage(damage int8, source rl.Rectangle) {
+	player.Health = max(0, player.Health-damage)
+	player.LastAction = Hurt
+
+	if player.Health == 0 {
+		player.ChangeState(Dead)
+		return
+	}
+
+	player.ChangeState(Knockback)
+
+	direction := float32(1)
+	if player.HitboxRect.X+player.HitboxRect.Width/2 < source.X+source.Width/2 {
+		direction = -1
+	}
+
+	player.Velocity = rl.NewVector2(direction*player.Profile.KnockbackForceX, player.Profile.KnockbackForceY)
+	player.OnGround = false
+	player.DashTimer = 0
+	player.JumpCuttable = false
+	player.InputLockTimer = player.Profile.KnockbackLockFrames
+	player.InvulnerabilityTimer = player.Profile.InvulnerabilityFrames
+}
+
+func (player *Player) IsVisible() bool {
+	if player.InvulnerabilityTimer == 0 {
+		return true
+	}
+
+	return (player.InvulnerabilityTimer/PLAYER_FLICKER_FRAMES)%2 == 0
+}
+
+// Respawn brings the player back to life, healed and standing still at the given position.
+func (player *Player) Respawn(position rl.Vector2) {
+	player.ChangeState(Falling)
+	player.Teleport(position)
+	player.Health = player.MaxHealth
+	player.InvulnerabilityTimer = 0
+	player.Velocity = rl.NewVector2(0, 0)
+	player.OnGround = false
 }
 
 func (player *Player) IsMoving() bool {
@@ -463,18 +1007,21 @@ func (player *Player) OpenCollidingClosedDoors(l *Level) {
 		}
 
 		if rl.CheckCollisionRecs(player.InteractiveRect, prop.HitboxRect) && player.HasKeyInInventory() {
-			l.Props[i].IsOpen = true
+			l.Props[i].Open()
 			l.Props[i].Walkable = true
+			l.RemoveCollisionable(&l.Props[i].HitboxRect)
 			player.RemoveKeyFromInventory()
 		}
 	}
-
-	l.LoadCollisionables()
 }
 
 func (player *Player) HasKeyInInventory() bool {
+	return player.HasPropInInventory(PropKey)
+}
+
+func (player *Player) HasPropInInventory(propType PropType) bool {
 	for _, item := range player.Inventory {
-		if item.Type == PropKey {
+		if item.Type == propType {
 			return true
 		}
 	}
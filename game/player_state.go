@@ -0,0 +1,161 @@
+package game
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type PlayerState int
+
+const (
+	Idle PlayerState = iota
+	Running
+	Jumping
+	WallJumping
+	Falling
+	Landing
+	WallSliding
+	Dashing
+	Knockback
+	Climbing
+	Swimming
+	Dead
+)
+
+// frames the player stays in the Landing state after touching the ground
+const PLAYER_LANDING_FRAMES int32 = SIMULATION_FPS / 12
+
+var playerStateName = map[PlayerState]string{
+	Idle:        "Idle",
+	Running:     "Running",
+	Jumping:     "Jumping",
+	WallJumping: "WallJumping",
+	Falling:     "Falling",
+	Landing:     "Landing",
+	WallSliding: "WallSliding",
+	Dashing:     "Dashing",
+	Knockback:   "Knockback",
+	Climbing:    "Climbing",
+	Swimming:    "Swimming",
+	Dead:        "Dead",
+}
+
+func (ps PlayerState) String() string {
+	return playerStateName[ps]
+}
+
+func (ps PlayerState) IsAirborne() bool {
+	return ps == Jumping || ps == WallJumping || ps == Falling || ps == WallSliding || ps == Knockback
+}
+
+var playerStateClips = map[PlayerState]string{
+	Idle:        "player_idle",
+	Running:     "player_run",
+	Jumping:     "player_idle",
+	WallJumping: "player_idle",
+	Falling:     "player_idle",
+	Landing:     "player_crouch",
+	WallSliding: "player_crouch",
+	Dashing:     "player_idle",
+	Knockback:   "player_idle",
+	Climbing:    "player_climb",
+	Swimming:    "player_swim",
+	Dead:        "player_idle",
+}
+
+type PlayerStateHooks struct {
+	Enter func(player *Player, from PlayerState)
+	Exit  func(player *Player, to PlayerState)
+}
+
+// PlayerTransitionListener is told about every state change, once the exit and enter hooks
+// have run.
+type PlayerTransitionListener func(player *Player, from PlayerState, to PlayerState)
+
+var playerStateHooks = map[PlayerState]PlayerStateHooks{
+	Dashing: {
+		// the dash speed is brought back to running values once it is over
+		Exit: func(player *Player, to PlayerState) {
+			player.DashTimer = 0
+			player.Velocity.X = rl.Clamp(player.Velocity.X, -player.Profile.MoveSpeed, player.Profile.MoveSpeed)
+			player.Velocity.Y = min(player.Velocity.Y*0.5, player.Profile.FallTerminalVelocity)
+		},
+	},
+	WallSliding: {
+		Exit: func(player *Player, to PlayerState) {
+			player.IsWallSliding = false
+		},
+	},
+	Dead: {
+		Enter: func(player *Player, from PlayerState) {
+			player.Velocity.X = 0
+			player.Velocity.Y = 0
+		},
+	},
+}
+
+func (player *Player) OnTransition(listener PlayerTransitionListener) {
+	player.TransitionListeners = append(player.TransitionListeners, listener)
+}
+
+func (player *Player) ChangeState(state PlayerState) {
+	if state == player.State {
+		return
+	}
+
+	from := player.State
+
+	if hooks, ok := playerStateHooks[from]; ok && hooks.Exit != nil {
+		hooks.Exit(player, state)
+	}
+
+	player.State = state
+	player.StateFrames = 0
+
+	if hooks, ok := playerStateHooks[state]; ok && hooks.Enter != nil {
+		hooks.Enter(player, from)
+	}
+
+	player.Animator.Play(playerStateClips[state])
+
+	for _, listener := range player.TransitionListeners {
+		listener(player, from, state)
+	}
+}
+
+// UpdateState moves to whichever state the physics of this frame ended up in. Jumps, dashes,
+// hits, deaths and ladders change the state straight away when they happen.
+func (player *Player) UpdateState() {
+	player.StateFrames++
+	player.ChangeState(player.NextState())
+}
+
+func (player *Player) NextState() PlayerState {
+	switch {
+	case player.State == Dead:
+		return Dead
+	case player.State == Climbing:
+		return Climbing
+	case player.IsDashing():
+		return Dashing
+	case player.State == Knockback && player.InputLockTimer > 0 && !player.OnGround:
+		return Knockback
+	case player.State == WallJumping && player.InputLockTimer > 0 && player.Velocity.Y < 0:
+		return WallJumping
+	case player.IsSwimming() && !player.OnGround:
+		return Swimming
+	case player.IsWallSliding && !player.OnGround:
+		return WallSliding
+	case !player.OnGround && player.Velocity.Y < 0:
+		return Jumping
+	case !player.OnGround:
+		return Falling
+	case player.State.IsAirborne():
+		return Landing
+	case player.State == Landing && player.StateFrames < PLAYER_LANDING_FRAMES:
+		return Landing
+	case player.Velocity.X != 0:
+		return Running
+	default:
+		return Idle
+	}
+}
@@ -0,0 +1,55 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestTransitionListenersSeeEveryChange(t *testing.T) {
+	player := InitPlayer(RegularCollision)
+
+	var transitions [][2]PlayerState
+	player.OnTransition(func(player *Player, from PlayerState, to PlayerState) {
+		if player.State != to || player.StateFrames != 0 {
+			t.Errorf("expected the listener to run once the player is %s, got %s", to, player.State)
+		}
+
+		transitions = append(transitions, [2]PlayerState{from, to})
+	})
+
+	player.ChangeState(Running)
+	player.ChangeState(Running)
+	player.ChangeState(Jumping)
+
+	expected := [][2]PlayerState{{Idle, Running}, {Running, Jumping}}
+	if len(transitions) != len(expected) || transitions[0] != expected[0] || transitions[1] != expected[1] {
+		t.Fatalf("expected %v, got %v", expected, transitions)
+	}
+}
+
+func TestStateHooks(t *testing.T) {
+	player := InitPlayer(RegularCollision)
+
+	// leaving a dash brings the speed back to running values
+	player.ChangeState(Dashing)
+	player.DashTimer = 5
+	player.Velocity.X = -player.Profile.DashSpeed
+	player.Velocity.Y = 100
+	player.ChangeState(Falling)
+
+	if player.DashTimer != 0 || player.Velocity.X != -player.Profile.MoveSpeed || player.Velocity.Y != 50 {
+		t.Fatalf("expected the dash exit hook to end the dash, timer %d velocity %v", player.DashTimer, player.Velocity)
+	}
+
+	player.ChangeState(WallSliding)
+	player.IsWallSliding = true
+	player.ChangeState(Falling)
+	if player.IsWallSliding {
+		t.Fatalf("expected the wall slide exit hook to let go of the wall")
+	}
+
+	player.Velocity.X = 80
+	player.ChangeState(Dead)
+	if player.Velocity.X != 0 || player.Velocity.Y != 0 {
+		t.Fatalf("expected the dead enter hook to stop the player, velocity %v", player.Velocity)
+	}
+}
@@ -0,0 +1,739 @@
+package game
+
+import (
+	"math"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"game3/collisions"
+	"game3/input"
+)
+
+func pressing(actions ...input.Action) input.State {
+	state := holding(actions...)
+	state.Pressed = state.Held
+
+	return state
+}
+
+// walkOffLedge leaves the player right after its first frame in the air.
+func walkOffLedge(t *testing.T) *Game {
+	g := NewGame(RegularCollision, 1)
+	g.Player.Teleport(rl.NewVector2(60, 88))
+
+	stepFrames(g, 10, input.State{})
+	if !g.Player.OnGround {
+		t.Fatalf("expected the player to stand on the ledge, position %v", g.Player.Position)
+	}
+
+	for frame := 0; g.Player.OnGround; frame++ {
+		if frame > int(SIMULATION_FPS) {
+			t.Fatalf("the player never walked off the ledge, position %v", g.Player.Position)
+		}
+
+		g.Tick(FIXED_DELTA, holding(input.MoveRight))
+	}
+
+	return g
+}
+
+// framesUntilLanding counts the frames it takes the player to land from the spawn point.
+func framesUntilLanding(t *testing.T) int {
+	g := NewGame(RegularCollision, 1)
+
+	for frame := 1; frame <= int(SIMULATION_FPS); frame++ {
+		g.Tick(FIXED_DELTA, input.State{})
+
+		if g.Player.OnGround {
+			return frame
+		}
+	}
+
+	t.Fatal("the player never landed")
+	return 0
+}
+
+func TestCoyoteTime(t *testing.T) {
+	g := walkOffLedge(t)
+	stepFrames(g, int(g.Player.Profile.CoyoteFrames)-2, holding(input.MoveRight))
+
+	g.Tick(FIXED_DELTA, pressing(input.Jump, input.MoveRight))
+	if g.Player.LastAction != Jump {
+		t.Fatalf("expected a jump on the last coyote frame")
+	}
+}
+
+func TestCoyoteTimeExpires(t *testing.T) {
+	g := walkOffLedge(t)
+	stepFrames(g, int(g.Player.Profile.CoyoteFrames)-1, holding(input.MoveRight))
+
+	g.Tick(FIXED_DELTA, pressing(input.Jump, input.MoveRight))
+	if g.Player.LastAction == Jump {
+		t.Fatalf("expected no jump once the coyote frames are over")
+	}
+}
+
+func TestJumpBuffer(t *testing.T) {
+	landing := framesUntilLanding(t)
+
+	for _, testCase := range []struct {
+		framesEarly int
+		jumps       bool
+	}{
+		{framesEarly: 1, jumps: true},
+		{framesEarly: int(PLAYER_JUMP_BUFFER_FRAMES), jumps: true},
+		{framesEarly: int(PLAYER_JUMP_BUFFER_FRAMES) + 1, jumps: false},
+	} {
+		g := NewGame(RegularCollision, 1)
+		stepFrames(g, landing-testCase.framesEarly-1, input.State{})
+		g.Tick(FIXED_DELTA, pressing(input.Jump))
+		stepFrames(g, testCase.framesEarly, holding(input.Jump))
+
+		jumped := g.Player.LastAction == Jump
+		if jumped != testCase.jumps {
+			t.Errorf("jump pressed %d frames before landing: expected jump %t, got %t", testCase.framesEarly, testCase.jumps, jumped)
+		}
+	}
+}
+
+func TestJumpBufferInFrameInspectorMode(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	stepFrames(g, int(SIMULATION_FPS), input.State{})
+	g.FrameInspectorMode = true
+
+	// the press happens while the simulation is stopped, it must survive until the next step
+	g.Tick(FIXED_DELTA, pressing(input.Jump))
+	stepFrames(g, 2*int(PLAYER_JUMP_BUFFER_FRAMES), holding(input.Jump))
+
+	g.StepRequested = true
+	g.Tick(FIXED_DELTA, holding(input.Jump))
+	if g.Player.LastAction != Jump {
+		t.Fatalf("expected the buffered jump to happen on the stepped frame")
+	}
+}
+
+// climbLadderFromLevel0 leaves the player holding up at the bottom of the shaft ladder.
+func climbLadderFromLevel0(t *testing.T) *Game {
+	g := NewGame(RegularCollision, 1)
+	if err := g.LoadLevel("Level_0"); err != nil {
+		t.Fatalf("error loading the level: %s", err)
+	}
+	g.Player.Teleport(rl.NewVector2(268, 150))
+
+	stepFrames(g, 10, input.State{})
+	if !g.Player.OnGround {
+		t.Fatalf("expected the player to stand at the bottom of the shaft, position %v", g.Player.Position)
+	}
+
+	stepFrames(g, 2, holding(input.MoveUp))
+	if g.Player.State != Climbing {
+		t.Fatalf("expected up to grab the ladder, got %s", g.Player.State)
+	}
+
+	return g
+}
+
+func TestLadderClimbsIntoTheLevelAbove(t *testing.T) {
+	g := climbLadderFromLevel0(t)
+
+	for frame := 0; g.Player.State == Climbing; frame++ {
+		if frame > 10*int(SIMULATION_FPS) {
+			t.Fatalf("the player never climbed out, level %s position %v", g.CurrentLevel.Name, g.Player.Position)
+		}
+
+		g.Tick(FIXED_DELTA, holding(input.MoveUp))
+	}
+
+	stepFrames(g, 10, input.State{})
+
+	if g.CurrentLevel.Name != "Level_1" {
+		t.Fatalf("expected the ladder to lead into Level_1, got %s", g.CurrentLevel.Name)
+	}
+
+	if !g.Player.OnGround || g.Player.Position.Y != 152 {
+		t.Fatalf("expected the player to stand on top of the ladder, position %v on ground %t", g.Player.Position, g.Player.OnGround)
+	}
+
+	stepFrames(g, int(SIMULATION_FPS), holding(input.MoveDown))
+
+	if g.CurrentLevel.Name != "Level_0" || g.Player.State != Climbing {
+		t.Fatalf("expected down to climb back into Level_0, got %s in %s", g.Player.State, g.CurrentLevel.Name)
+	}
+}
+
+func TestJumpingOffLadder(t *testing.T) {
+	g := climbLadderFromLevel0(t)
+	stepFrames(g, int(SIMULATION_FPS)/2, holding(input.MoveUp))
+
+	g.Tick(FIXED_DELTA, pressing(input.Jump))
+	if g.Player.State != Jumping || g.Player.Velocity.Y >= 0 {
+		t.Fatalf("expected a jump off the ladder, got %s with velocity %v", g.Player.State, g.Player.Velocity)
+	}
+
+	stepFrames(g, int(SIMULATION_FPS)/4, input.State{})
+	if g.Player.State == Climbing {
+		t.Fatalf("expected the player to stay off the ladder without pressing up or down")
+	}
+}
+
+func TestFallingIntoWaterSplashesAndFloats(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	if err := g.LoadLevel("Level_2"); err != nil {
+		t.Fatalf("error loading the level: %s", err)
+	}
+	g.Player.Teleport(rl.NewVector2(40, 100))
+	ambientParticles := len(g.CurrentLevel.Particles)
+
+	for frame := 0; g.Player.State != Swimming; frame++ {
+		if frame > int(SIMULATION_FPS) {
+			t.Fatalf("the player never reached the water, position %v", g.Player.Position)
+		}
+
+		g.Tick(FIXED_DELTA, input.State{})
+	}
+
+	if len(g.CurrentLevel.Particles) <= ambientParticles || len(g.CurrentVFXs) == 0 {
+		t.Fatalf("expected a splash when falling into the water")
+	}
+
+	stepFrames(g, 3*int(SIMULATION_FPS), input.State{})
+
+	if g.Player.OnGround || g.Player.State != Swimming {
+		t.Fatalf("expected the player to float, got %s at %v", g.Player.State, g.Player.Position)
+	}
+
+	if len(g.CurrentLevel.Particles) != ambientParticles {
+		t.Fatalf("expected the splash particles to be gone, %d left over", len(g.CurrentLevel.Particles)-ambientParticles)
+	}
+
+	floatingAt := g.Player.Position.Y
+	g.Tick(FIXED_DELTA, pressing(input.Jump))
+	stepFrames(g, int(SIMULATION_FPS)/10, input.State{})
+
+	if g.Player.Position.Y >= floatingAt {
+		t.Fatalf("expected a swim stroke to push the player up, from %f to %f", floatingAt, g.Player.Position.Y)
+	}
+}
+
+// pickUpDash stands the player on the dash pickup in Level_4 and interacts with it.
+func pickUpDash(t *testing.T, g *Game) {
+	g.Player.Teleport(rl.NewVector2(48, 150))
+	stepFrames(g, 10, input.State{})
+
+	var interact input.State
+	interact.Set(input.Interact, false, false, true)
+	g.Tick(FIXED_DELTA, interact)
+	// the interaction is carried out on the following step
+	g.Tick(FIXED_DELTA, input.State{})
+
+	if !g.Player.HasPropInInventory(PropDash) {
+		t.Fatalf("expected the player to pick up the dash, position %v", g.Player.Position)
+	}
+}
+
+func TestDash(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+	g.Player.Teleport(rl.NewVector2(24, 150))
+	stepFrames(g, 10, input.State{})
+
+	g.Tick(FIXED_DELTA, pressing(input.Dash, input.MoveRight))
+	if g.Player.State == Dashing {
+		t.Fatalf("expected no dash before picking it up")
+	}
+
+	pickUpDash(t, g)
+
+	// dashing in the air, gravity is off for the whole dash
+	g.Player.Teleport(rl.NewVector2(60, 140))
+	g.Tick(FIXED_DELTA, input.State{})
+	g.Tick(FIXED_DELTA, pressing(input.Dash, input.MoveRight))
+
+	// the dash starts once the step has moved the player, it moves it on the following ones
+	dashFrames := 0
+	for ; g.Player.State == Dashing; dashFrames++ {
+		if g.Player.Velocity.Y != 0 || g.Player.Velocity.X != g.Player.Profile.DashSpeed {
+			t.Fatalf("expected a straight dash with no gravity, velocity %v", g.Player.Velocity)
+		}
+
+		g.Tick(FIXED_DELTA, holding(input.MoveRight))
+	}
+
+	if dashFrames != int(g.Player.Profile.DashFrames) {
+		t.Fatalf("expected the dash to last %d frames, got %d", g.Player.Profile.DashFrames, dashFrames)
+	}
+
+	g.Tick(FIXED_DELTA, pressing(input.Dash, input.MoveRight))
+	if g.Player.State == Dashing || g.Player.DashAvailable {
+		t.Fatalf("expected a single dash per jump")
+	}
+
+	for frame := 0; !g.Player.OnGround; frame++ {
+		if frame > int(SIMULATION_FPS) {
+			t.Fatalf("the player never landed after the dash, position %v", g.Player.Position)
+		}
+
+		g.Tick(FIXED_DELTA, input.State{})
+	}
+
+	// landing gives the dash back, once the cooldown is over
+	if !g.Player.DashAvailable || g.Player.DashCooldownTimer == 0 {
+		t.Fatalf("expected the cooldown to outlast the fall")
+	}
+
+	g.Tick(FIXED_DELTA, pressing(input.Dash, input.MoveLeft))
+	if g.Player.State == Dashing {
+		t.Fatalf("expected no dash during the cooldown")
+	}
+
+	stepFrames(g, int(g.Player.DashCooldownTimer), input.State{})
+	g.Tick(FIXED_DELTA, pressing(input.Dash, input.MoveLeft))
+	if g.Player.State != Dashing {
+		t.Fatalf("expected the dash to be available again after landing, got %s", g.Player.State)
+	}
+}
+
+// newTestLevel builds an empty level colliding only with the given colliders.
+func newTestLevel(colliders ...*collisions.Collider) *Level {
+	level := &Level{
+		Width:          320,
+		Height:         180,
+		Collisionables: colliders,
+		CollisionGrid:  collisions.NewSpatialGrid(collisions.GRID_CELL_SIZE),
+	}
+
+	for _, collider := range colliders {
+		level.CollisionGrid.Insert(collider)
+	}
+
+	return level
+}
+
+func solidTile(x float32, y float32) *collisions.Collider {
+	rect := rl.NewRectangle(x, y, TileSize, TileSize)
+
+	return collisions.NewCollider(&rect, collisions.SolidCollider)
+}
+
+func TestRayCastedFallDoesNotTunnel(t *testing.T) {
+	level := newTestLevel(solidTile(96, 120), solidTile(104, 120))
+	player := InitPlayer(RayCastedCollision)
+
+	// a few times the floor thickness every step, a discrete check would skip over it
+	player.Profile.FallTerminalVelocity = 4000
+	player.Teleport(rl.NewVector2(100, 0))
+	player.Velocity.Y = player.Profile.FallTerminalVelocity
+
+	for range SIMULATION_FPS / 4 {
+		player.Tick(FIXED_DELTA, level, input.State{})
+	}
+
+	if !player.OnGround || player.Position.Y != 120-player.HitboxRect.Height {
+		t.Fatalf("expected the player to land on the floor, got %v", player.Position)
+	}
+}
+
+// hillLevel rises with a 45° slope onto a plateau and goes back down with a 22.5° one, all
+// over a floor whose top is at 120.
+func hillLevel() *Level {
+	var colliders []*collisions.Collider
+
+	for column := range 20 {
+		colliders = append(colliders, solidTile(float32(column)*TileSize, 120))
+	}
+
+	for column := 6; column < 10; column++ {
+		colliders = append(colliders, solidTile(float32(column)*TileSize, 112))
+	}
+
+	slope := func(column int, leftHeight float32, rightHeight float32) *collisions.Collider {
+		rect := rl.NewRectangle(float32(column)*TileSize, 112, TileSize, TileSize)
+		return collisions.NewSlopeCollider(&rect, leftHeight, rightHeight)
+	}
+
+	colliders = append(colliders, slope(5, 0, 8), slope(10, 8, 4), slope(11, 4, 0))
+
+	return newTestLevel(colliders...)
+}
+
+// groundUnder returns the top of whatever the player would stand on at its position.
+func groundUnder(level *Level, hitbox rl.Rectangle) float32 {
+	ground := float32(level.Height)
+
+	for _, collider := range level.Collisionables {
+		if collider.Kind == collisions.SlopeCollider {
+			if surfaceY, isAbove := collider.GetHighestSurfaceY(hitbox.X, hitbox.X+hitbox.Width); isAbove {
+				ground = min(ground, surfaceY)
+			}
+
+			continue
+		}
+
+		if hitbox.X < collider.Rect.X+collider.Rect.Width && hitbox.X+hitbox.Width > collider.Rect.X {
+			ground = min(ground, collider.Rect.Y)
+		}
+	}
+
+	return ground
+}
+
+func TestWalkingOverSlopesStaysOnTheGround(t *testing.T) {
+	for _, collisionSystem := range []CollisionSystem{RegularCollision, RayCastedCollision} {
+		level := hillLevel()
+		player := InitPlayer(collisionSystem)
+		player.Teleport(rl.NewVector2(8, 112))
+
+		for range 10 {
+			player.Tick(FIXED_DELTA, level, input.State{})
+		}
+
+		walk := func(direction input.Action, done func() bool) {
+			for frame := 0; !done(); frame++ {
+				if frame > 3*int(SIMULATION_FPS) {
+					t.Fatalf("collision system %d: the player got stuck at %v", collisionSystem, player.Position)
+				}
+
+				player.Tick(FIXED_DELTA, level, holding(direction))
+
+				bottom := player.Position.Y + player.HitboxRect.Height
+				if expected := groundUnder(level, player.HitboxRect); !player.OnGround || math.Abs(float64(bottom-expected)) > 0.05 {
+					t.Fatalf("collision system %d: frame %d expected to stand on the ground at %f, on ground %t with its feet at %f", collisionSystem, frame, expected, player.OnGround, bottom)
+				}
+			}
+		}
+
+		walk(input.MoveRight, func() bool { return player.Position.X > 120 })
+		walk(input.MoveLeft, func() bool { return player.Position.X < 8 })
+	}
+}
+
+// oneWayLevel has a one way platform 20px over the floor, low enough to jump through.
+func oneWayLevel() *Level {
+	var colliders []*collisions.Collider
+
+	for column := range 40 {
+		colliders = append(colliders, solidTile(float32(column)*TileSize, 160))
+	}
+
+	platform := rl.NewRectangle(64, 140, 40, TileSize)
+	colliders = append(colliders, collisions.NewCollider(&platform, collisions.OneWayCollider))
+
+	return newTestLevel(colliders...)
+}
+
+func feetAt(player *Player, y float32) bool {
+	return math.Abs(float64(player.Position.Y+player.HitboxRect.Height-y)) <= float64(collisions.CONTACT_TOLERANCE)
+}
+
+func tickUntilGrounded(t *testing.T, player *Player, level *Level, actions input.State) {
+	for frame := 0; !player.OnGround; frame++ {
+		if frame > int(SIMULATION_FPS) {
+			t.Fatalf("the player never landed, position %v", player.Position)
+		}
+
+		player.Tick(FIXED_DELTA, level, actions)
+	}
+}
+
+func TestLandingOnOneWayPlatform(t *testing.T) {
+	level := oneWayLevel()
+	player := InitPlayer(RegularCollision)
+	player.Teleport(rl.NewVector2(80, 100))
+
+	tickUntilGrounded(t, player, level, input.State{})
+
+	if !feetAt(player, 140) || !player.Contacts.OnOneWay {
+		t.Fatalf("expected the player to land on the platform, got %v", player.Position)
+	}
+}
+
+func TestJumpingUpThroughOneWayPlatform(t *testing.T) {
+	level := oneWayLevel()
+	player := InitPlayer(RegularCollision)
+	player.Teleport(rl.NewVector2(80, 152))
+	tickUntilGrounded(t, player, level, input.State{})
+
+	player.Tick(FIXED_DELTA, level, pressing(input.Jump))
+	highest := player.Position.Y
+	for frame := 0; player.Velocity.Y < 0; frame++ {
+		if frame > int(SIMULATION_FPS) {
+			t.Fatalf("the jump never peaked, position %v", player.Position)
+		}
+
+		player.Tick(FIXED_DELTA, level, holding(input.Jump))
+		highest = min(highest, player.Position.Y)
+	}
+
+	if highest+player.HitboxRect.Height >= 140 {
+		t.Fatalf("expected the player to go up through the platform, peaked at %f", highest)
+	}
+
+	tickUntilGrounded(t, player, level, input.State{})
+	if !feetAt(player, 140) {
+		t.Fatalf("expected the player to land on the platform from below, got %v", player.Position)
+	}
+}
+
+func TestDroppingThroughOneWayPlatform(t *testing.T) {
+	level := oneWayLevel()
+	player := InitPlayer(RegularCollision)
+	player.Teleport(rl.NewVector2(80, 100))
+	tickUntilGrounded(t, player, level, input.State{})
+
+	player.Tick(FIXED_DELTA, level, pressing(input.Jump, input.MoveDown))
+	if player.DropThroughTimer <= 0 || player.Velocity.Y < 0 {
+		t.Fatalf("expected down and jump to drop through instead of jumping")
+	}
+
+	var ignoredFrames int32
+	for ; player.DropThroughTimer > 0; ignoredFrames++ {
+		if ignoredFrames > SIMULATION_FPS {
+			t.Fatalf("the drop through never ended")
+		}
+
+		player.Tick(FIXED_DELTA, level, input.State{})
+	}
+
+	if ignoredFrames != player.Profile.DropThroughFrames {
+		t.Fatalf("expected the platform to be ignored for %d frames, got %d", player.Profile.DropThroughFrames, ignoredFrames)
+	}
+
+	tickUntilGrounded(t, player, level, input.State{})
+	if !feetAt(player, 160) {
+		t.Fatalf("expected the player to fall down to the floor, got %v", player.Position)
+	}
+
+	// the platform is solid again once the drop is over
+	player.Teleport(rl.NewVector2(80, 100))
+	player.OnGround = false
+	tickUntilGrounded(t, player, level, input.State{})
+	if !feetAt(player, 140) {
+		t.Fatalf("expected to land on the platform once the drop was over, got %v", player.Position)
+	}
+}
+
+func spikesAt(x float32, y float32) *Prop {
+	return &Prop{Type: PropSpikes, Damage: SPIKES_DAMAGE, HitboxRect: rl.NewRectangle(x, y, TileSize, TileSize)}
+}
+
+func TestHazardsHurtOncePerInvulnerability(t *testing.T) {
+	level := newTestLevel()
+	level.Props = []*Prop{spikesAt(100, 100)}
+	player := InitPlayer(RegularCollision)
+	player.Teleport(rl.NewVector2(100, 100))
+
+	player.CheckHazards(level)
+	if player.Health != player.MaxHealth-SPIKES_DAMAGE || player.State != Knockback {
+		t.Fatalf("expected the spikes to hurt and knock the player back, health %d state %s", player.Health, player.State)
+	}
+
+	for range player.Profile.InvulnerabilityFrames - 1 {
+		player.CheckHazards(level)
+	}
+
+	if player.Health != player.MaxHealth-SPIKES_DAMAGE || player.InvulnerabilityTimer == 0 {
+		t.Fatalf("expected no damage while invulnerable, health %d", player.Health)
+	}
+
+	player.CheckHazards(level)
+	player.CheckHazards(level)
+	if player.Health != player.MaxHealth-2*SPIKES_DAMAGE {
+		t.Fatalf("expected the spikes to hurt again once invulnerability is over, health %d", player.Health)
+	}
+}
+
+func TestKnockbackPushesAwayFromTheHazard(t *testing.T) {
+	for _, testCase := range []struct {
+		hazardX   float32
+		direction float32
+	}{
+		{hazardX: 104, direction: -1},
+		{hazardX: 96, direction: 1},
+	} {
+		player := InitPlayer(RegularCollision)
+		player.Teleport(rl.NewVector2(100, 100))
+		player.TakeDamage(1, rl.NewRectangle(testCase.hazardX, 100, TileSize, TileSize))
+
+		if player.Velocity.X != testCase.direction*player.Profile.KnockbackForceX || player.Velocity.Y != player.Profile.KnockbackForceY {
+			t.Errorf("hazard at %f: expected to be knocked %f, got %v", testCase.hazardX, testCase.direction, player.Velocity)
+		}
+
+		if player.InputLockTimer != player.Profile.KnockbackLockFrames {
+			t.Errorf("hazard at %f: expected the input to be locked", testCase.hazardX)
+		}
+	}
+}
+
+func TestPlayerOnlyDiesWithNoHealthLeft(t *testing.T) {
+	player := InitPlayer(RegularCollision)
+	source := rl.NewRectangle(104, 140, TileSize, TileSize)
+
+	for range player.MaxHealth/SPIKES_DAMAGE - 1 {
+		player.TakeDamage(SPIKES_DAMAGE, source)
+		if player.State == Dead {
+			t.Fatalf("expected the player to survive with %d health", player.Health)
+		}
+	}
+
+	player.TakeDamage(SPIKES_DAMAGE, source)
+	if player.State != Dead || player.Health != 0 {
+		t.Fatalf("expected the player dead at zero health, got %s with %d", player.State, player.Health)
+	}
+}
+
+func floorLevel() *Level {
+	var colliders []*collisions.Collider
+	for column := range 40 {
+		colliders = append(colliders, solidTile(float32(column)*TileSize, 160))
+	}
+
+	return newTestLevel(colliders...)
+}
+
+// jumpApex jumps from the floor holding jump for the given frames and returns the highest
+// point reached.
+func jumpApex(t *testing.T, heldFrames int) float32 {
+	level := floorLevel()
+	player := InitPlayer(RegularCollision)
+	player.Teleport(rl.NewVector2(100, 152))
+	tickUntilGrounded(t, player, level, input.State{})
+
+	player.Tick(FIXED_DELTA, level, pressing(input.Jump))
+	highest := player.Position.Y
+	for frame := 1; !player.OnGround; frame++ {
+		if frame > 2*int(SIMULATION_FPS) {
+			t.Fatalf("the player never landed, position %v", player.Position)
+		}
+
+		actions := input.State{}
+		if frame < heldFrames {
+			actions = holding(input.Jump)
+		}
+
+		player.Tick(FIXED_DELTA, level, actions)
+		highest = min(highest, player.Position.Y)
+	}
+
+	return highest
+}
+
+func TestReleasingJumpEarlyCutsTheJump(t *testing.T) {
+	full := jumpApex(t, 2*int(SIMULATION_FPS))
+	short := jumpApex(t, 5)
+
+	// y grows downwards, a lower apex has a bigger y
+	if short <= full+TileSize {
+		t.Fatalf("expected releasing jump early to peak clearly lower, full jump %f, short hop %f", full, short)
+	}
+}
+
+func TestGravityMultiplier(t *testing.T) {
+	player := InitPlayer(RegularCollision)
+
+	for _, testCase := range []struct {
+		name      string
+		velocityY float32
+		jumpHeld  bool
+		expected  float32
+	}{
+		{"rising", -150, true, 1},
+		{"apex holding jump", -10, true, player.Profile.ApexGravityMultiplier},
+		{"apex without jump", -10, false, 1},
+		{"falling", 150, false, player.Profile.FallGravityMultiplier},
+		{"falling out of the apex", player.Profile.ApexThreshold + 1, true, player.Profile.FallGravityMultiplier},
+	} {
+		player.Velocity.Y = testCase.velocityY
+		player.JumpHeld = testCase.jumpHeld
+
+		if multiplier := player.GetGravityMultiplier(); multiplier != testCase.expected {
+			t.Errorf("%s: expected a multiplier of %f, got %f", testCase.name, testCase.expected, multiplier)
+		}
+	}
+}
+
+func TestFallGravityIsApplied(t *testing.T) {
+	level := floorLevel()
+	player := InitPlayer(RegularCollision)
+	player.Teleport(rl.NewVector2(100, 20))
+	player.Velocity.Y = 50
+
+	player.Tick(FIXED_DELTA, level, input.State{})
+
+	expected := 50 + player.Profile.Gravity*player.Profile.FallGravityMultiplier*FIXED_DELTA
+	if math.Abs(float64(player.Velocity.Y-expected)) > 0.001 {
+		t.Fatalf("expected the fall gravity to be applied, velocity %f instead of %f", player.Velocity.Y, expected)
+	}
+}
+
+// wallLevel has a wall running all the way down the east side of the floor.
+func wallLevel() *Level {
+	level := floorLevel()
+	for row := range 20 {
+		wall := solidTile(160, float32(row)*TileSize)
+		level.Collisionables = append(level.Collisionables, wall)
+		level.CollisionGrid.Insert(wall)
+	}
+
+	return level
+}
+
+// slideDownTheWall falls against the wall until the player is sliding down it.
+func slideDownTheWall(t *testing.T, level *Level) *Player {
+	player := InitPlayer(RegularCollision)
+	player.Teleport(rl.NewVector2(148, 20))
+
+	for frame := 0; !player.IsWallSliding; frame++ {
+		if frame > int(SIMULATION_FPS) {
+			t.Fatalf("the player never grabbed the wall, position %v", player.Position)
+		}
+
+		player.Tick(FIXED_DELTA, level, holding(input.MoveRight))
+	}
+
+	return player
+}
+
+func TestWallSlideIsCappedAtTheSlideSpeed(t *testing.T) {
+	level := wallLevel()
+	player := slideDownTheWall(t, level)
+
+	for range SIMULATION_FPS / 4 {
+		player.Tick(FIXED_DELTA, level, holding(input.MoveRight))
+
+		if !player.IsWallSliding || player.Velocity.Y > player.Profile.WallSlideSpeed {
+			t.Fatalf("expected to slide down the wall at most at %f, sliding %t at %f",
+				player.Profile.WallSlideSpeed, player.IsWallSliding, player.Velocity.Y)
+		}
+	}
+
+	if player.Velocity.Y != player.Profile.WallSlideSpeed {
+		t.Fatalf("expected to settle at the slide speed, got %f", player.Velocity.Y)
+	}
+}
+
+func TestWallJumpPushesAwayAndLocksSteering(t *testing.T) {
+	level := wallLevel()
+	player := slideDownTheWall(t, level)
+
+	player.Tick(FIXED_DELTA, level, pressing(input.Jump, input.MoveRight))
+
+	if player.LastAction != WallJump || player.Velocity.X != -player.Profile.WallJumpForceX || player.Velocity.Y >= 0 {
+		t.Fatalf("expected a wall jump up and away from the east wall, last action %d, velocity %v",
+			player.LastAction, player.Velocity)
+	}
+
+	// steering back into the wall is ignored while the lock lasts
+	for frame := range player.Profile.WallJumpLockFrames {
+		player.Tick(FIXED_DELTA, level, holding(input.Jump, input.MoveRight))
+
+		if player.Velocity.X != -player.Profile.WallJumpForceX {
+			t.Fatalf("expected the push to be kept on locked frame %d, got %f", frame, player.Velocity.X)
+		}
+	}
+
+	player.Tick(FIXED_DELTA, level, holding(input.Jump, input.MoveRight))
+
+	if player.Velocity.X <= -player.Profile.WallJumpForceX {
+		t.Fatalf("expected to steer back once the lock is over, got %f", player.Velocity.X)
+	}
+}
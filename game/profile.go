@@ -0,0 +1,277 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"game3/ini"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_PROFILE_PATH   string = "movement.ini"
+	DEFAULT_PROFILE_PRESET string = "default"
+)
+
+// MovementProfile holds every value that shapes how the player moves. Durations are kept
+// in simulation frames, the profile file writes them in seconds.
+type MovementProfile struct {
+	Gravity                float32
+	FallTerminalVelocity   float32
+	MoveSpeed              float32
+	Acceleration           float32
+	Deceleration           float32
+	JumpForce              float32
+	DropThroughFrames      int32
+	CoyoteFrames           int32
+	JumpBufferFrames       int32
+	JumpCutMultiplier      float32
+	ApexThreshold          float32
+	ApexGravityMultiplier  float32
+	FallGravityMultiplier  float32
+	WallSlideSpeed         float32
+	WallJumpForceX         float32
+	WallJumpForceY         float32
+	WallJumpLockFrames     int32
+	DashSpeed              float32
+	DashFrames             int32
+	DashCooldownFrames     int32
+	KnockbackForceX        float32
+	KnockbackForceY        float32
+	KnockbackLockFrames    int32
+	InvulnerabilityFrames  int32
+	ClimbSpeed             float32
+	WaterGravityMultiplier float32
+	WaterBuoyancy          float32
+	WaterDrag              float32
+	SwimStrokeForce        float32
+	SwimSpeedMultiplier    float32
+}
+
+type MovementProfiles map[string]MovementProfile
+
+func DefaultMovementProfile() MovementProfile {
+	return MovementProfile{
+		Gravity:                GRAVITY,
+		FallTerminalVelocity:   FALL_TERMINAL_VELOCITY,
+		MoveSpeed:              PLAYER_MOVE_SPEED,
+		Acceleration:           PLAYER_ACCELERATION,
+		Deceleration:           PLAYER_DECELERATION,
+		JumpForce:              PLAYER_JUMP_FORCE,
+		DropThroughFrames:      PLAYER_DROP_THROUGH_FRAMES,
+		CoyoteFrames:           PLAYER_COYOTE_FRAMES,
+		JumpBufferFrames:       PLAYER_JUMP_BUFFER_FRAMES,
+		JumpCutMultiplier:      PLAYER_JUMP_CUT_MULTIPLIER,
+		ApexThreshold:          PLAYER_APEX_THRESHOLD,
+		ApexGravityMultiplier:  PLAYER_APEX_GRAVITY_MULTIPLIER,
+		FallGravityMultiplier:  PLAYER_FALL_GRAVITY_MULTIPLIER,
+		WallSlideSpeed:         PLAYER_WALL_SLIDE_SPEED,
+		WallJumpForceX:         PLAYER_WALL_JUMP_FORCE_X,
+		WallJumpForceY:         PLAYER_WALL_JUMP_FORCE_Y,
+		WallJumpLockFrames:     PLAYER_WALL_JUMP_LOCK_FRAMES,
+		DashSpeed:              PLAYER_DASH_SPEED,
+		DashFrames:             PLAYER_DASH_FRAMES,
+		DashCooldownFrames:     PLAYER_DASH_COOLDOWN_FRAMES,
+		KnockbackForceX:        PLAYER_KNOCKBACK_FORCE_X,
+		KnockbackForceY:        PLAYER_KNOCKBACK_FORCE_Y,
+		KnockbackLockFrames:    PLAYER_KNOCKBACK_LOCK_FRAMES,
+		InvulnerabilityFrames:  PLAYER_INVULNERABILITY_FRAMES,
+		ClimbSpeed:             PLAYER_CLIMB_SPEED,
+		WaterGravityMultiplier: PLAYER_WATER_GRAVITY_MULTIPLIER,
+		WaterBuoyancy:          PLAYER_WATER_BUOYANCY,
+		WaterDrag:              PLAYER_WATER_DRAG,
+		SwimStrokeForce:        PLAYER_SWIM_STROKE_FORCE,
+		SwimSpeedMultiplier:    PLAYER_SWIM_SPEED_MULTIPLIER,
+	}
+}
+
+// fields maps the names used in the profile file to the values they set. Pointers to int32
+// are frame counts, written in seconds in the file.
+func (p *MovementProfile) fields() map[string]any {
+	return map[string]any{
+		"gravity":                  &p.Gravity,
+		"fall_terminal_velocity":   &p.FallTerminalVelocity,
+		"move_speed":               &p.MoveSpeed,
+		"acceleration":             &p.Acceleration,
+		"deceleration":             &p.Deceleration,
+		"jump_force":               &p.JumpForce,
+		"drop_through_time":        &p.DropThroughFrames,
+		"coyote_time":              &p.CoyoteFrames,
+		"jump_buffer_time":         &p.JumpBufferFrames,
+		"jump_cut_multiplier":      &p.JumpCutMultiplier,
+		"apex_threshold":           &p.ApexThreshold,
+		"apex_gravity_multiplier":  &p.ApexGravityMultiplier,
+		"fall_gravity_multiplier":  &p.FallGravityMultiplier,
+		"wall_slide_speed":         &p.WallSlideSpeed,
+		"wall_jump_force_x":        &p.WallJumpForceX,
+		"wall_jump_force_y":        &p.WallJumpForceY,
+		"wall_jump_lock_time":      &p.WallJumpLockFrames,
+		"dash_speed":               &p.DashSpeed,
+		"dash_time":                &p.DashFrames,
+		"dash_cooldown_time":       &p.DashCooldownFrames,
+		"knockback_force_x":        &p.KnockbackForceX,
+		"knockback_force_y":        &p.KnockbackForceY,
+		"knockback_lock_time":      &p.KnockbackLockFrames,
+		"invulnerability_time":     &p.InvulnerabilityFrames,
+		"climb_speed":              &p.ClimbSpeed,
+		"water_gravity_multiplier": &p.WaterGravityMultiplier,
+		"water_buoyancy":           &p.WaterBuoyancy,
+		"water_drag":               &p.WaterDrag,
+		"swim_stroke_force":        &p.SwimStrokeForce,
+		"swim_speed_multiplier":    &p.SwimSpeedMultiplier,
+	}
+}
+
+func (p *MovementProfile) set(name string, value float64) bool {
+	switch field := p.fields()[name].(type) {
+	case *float32:
+		*field = float32(value)
+	case *int32:
+		*field = int32(math.Round(value * float64(SIMULATION_FPS)))
+	default:
+		return false
+	}
+
+	return true
+}
+
+// LoadMovementProfile reads the profile file and returns the given preset. A missing file
+// is not an error as long as the default preset is asked for.
+func LoadMovementProfile(path string, preset string) (MovementProfile, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) && preset == DEFAULT_PROFILE_PRESET {
+		return DefaultMovementProfile(), nil
+	}
+	if err != nil {
+		return MovementProfile{}, err
+	}
+	defer file.Close()
+
+	profiles, err := ParseMovementProfiles(file)
+	if err != nil {
+		return MovementProfile{}, err
+	}
+
+	return profiles.Get(preset)
+}
+
+// ParseMovementProfiles reads a list of "name = value" lines. The ones before the first
+// section override the built in values, and every [section] is a named preset overriding
+// those in turn:
+//
+//	gravity = 700
+//
+//	[floaty]
+//	gravity = 500
+func ParseMovementProfiles(r io.Reader) (MovementProfiles, error) {
+	base := DefaultMovementProfile()
+	overrides := map[string]map[string]float64{}
+	var presets []string
+
+	err := ini.Read(r, func(line ini.Line) error {
+		if line.IsSection {
+			if line.Section == "" || line.Section == DEFAULT_PROFILE_PRESET {
+				return fmt.Errorf("invalid preset name %q", line.Section)
+			}
+			if _, exists := overrides[line.Section]; exists {
+				return fmt.Errorf("duplicated preset %q", line.Section)
+			}
+
+			overrides[line.Section] = map[string]float64{}
+			presets = append(presets, line.Section)
+			return nil
+		}
+
+		if !line.HasValue {
+			return errors.New("expected \"name = value\"")
+		}
+
+		value, err := strconv.ParseFloat(line.Value, 32)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %q", line.Name, line.Value)
+		}
+
+		// settings before the first section make up the default preset
+		if line.Section == "" {
+			if !base.set(line.Name, value) {
+				return fmt.Errorf("unknown setting %q", line.Name)
+			}
+
+			return nil
+		}
+
+		if _, ok := base.fields()[line.Name]; !ok {
+			return fmt.Errorf("unknown setting %q", line.Name)
+		}
+
+		overrides[line.Section][line.Name] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := MovementProfiles{DEFAULT_PROFILE_PRESET: base}
+	for _, preset := range presets {
+		profile := base
+		for name, value := range overrides[preset] {
+			profile.set(name, value)
+		}
+
+		profiles[preset] = profile
+	}
+
+	return profiles, nil
+}
+
+func (mp MovementProfiles) Get(preset string) (MovementProfile, error) {
+	profile, ok := mp[preset]
+	if !ok {
+		return MovementProfile{}, fmt.Errorf("unknown movement preset %q, available: %s", preset, strings.Join(mp.Names(), ", "))
+	}
+
+	return profile, nil
+}
+
+func (mp MovementProfiles) Names() []string {
+	names := make([]string, 0, len(mp))
+	for name := range mp {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ProfileWatcher tells when the profile file was modified since the last time it was seen.
+type ProfileWatcher struct {
+	Path    string
+	ModTime time.Time
+}
+
+func NewProfileWatcher(path string) *ProfileWatcher {
+	watcher := &ProfileWatcher{Path: path}
+	watcher.Changed()
+
+	return watcher
+}
+
+func (pw *ProfileWatcher) Changed() bool {
+	info, err := os.Stat(pw.Path)
+	if err != nil {
+		return false
+	}
+
+	if info.ModTime().Equal(pw.ModTime) {
+		return false
+	}
+
+	pw.ModTime = info.ModTime()
+
+	return true
+}
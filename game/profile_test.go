@@ -0,0 +1,47 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShippedProfileMatchesDefaults(t *testing.T) {
+	profile, err := LoadMovementProfile("../"+DEFAULT_PROFILE_PATH, DEFAULT_PROFILE_PRESET)
+	if err != nil {
+		t.Fatalf("error loading the movement profile: %s", err)
+	}
+
+	if profile != DefaultMovementProfile() {
+		t.Fatalf("the default preset drifted from the built in values:\n%+v\n%+v", profile, DefaultMovementProfile())
+	}
+}
+
+func TestPresetsOverrideTheBaseValues(t *testing.T) {
+	profiles, err := ParseMovementProfiles(strings.NewReader("move_speed = 80\ncoyote_time = 0.5\n\n[floaty]\ngravity = 100\n"))
+	if err != nil {
+		t.Fatalf("error parsing profiles: %s", err)
+	}
+
+	floaty, err := profiles.Get("floaty")
+	if err != nil {
+		t.Fatalf("error getting the preset: %s", err)
+	}
+
+	if floaty.Gravity != 100 || floaty.MoveSpeed != 80 {
+		t.Fatalf("expected the preset to build on the base values, got %+v", floaty)
+	}
+
+	if floaty.CoyoteFrames != SIMULATION_FPS/2 {
+		t.Fatalf("expected durations in seconds to become frames, got %d", floaty.CoyoteFrames)
+	}
+
+	if _, err := profiles.Get("missing"); err == nil {
+		t.Fatalf("expected an error for an unknown preset")
+	}
+}
+
+func TestUnknownProfileSettingIsAnError(t *testing.T) {
+	if _, err := ParseMovementProfiles(strings.NewReader("[heavy]\ngravty = 900\n")); err == nil {
+		t.Fatalf("expected an error for a misspelled setting")
+	}
+}
@@ -6,29 +6,33 @@ import (
 
 type PropType int
 
+const SPIKES_DAMAGE int8 = 2
+
 const (
 	PropKey PropType = iota
 	PropDoor
 	PropSpikes
+	PropPlatform
+	PropDash
+	PropLadder
+	PropWater
 	PropGeneral
 )
 
 type Prop struct {
-	Type                   PropType
-	Pickable               bool
-	Walkable               bool
-	Pushable               bool
-	Sprite                 rl.Texture2D
-	Position               rl.Vector2
-	Width                  float32
-	Height                 float32
-	IsAnimated             bool
-	CurrentFrame           int32
-	AnimationFramesCounter int32
-	FramesCounter          int32
-	FramesSpeed            int32
-	HitboxRect             rl.Rectangle
-	IsOpen                 bool
+	Type             PropType
+	Pickable         bool
+	Walkable         bool
+	Pushable         bool
+	Sprite           rl.Texture2D
+	Position         rl.Vector2
+	PreviousPosition rl.Vector2
+	Width            float32
+	Height           float32
+	Animator         *Animator
+	HitboxRect       rl.Rectangle
+	IsOpen           bool
+	Damage           int8
 }
 
 type LDtkEntityCustomField struct {
@@ -61,29 +65,58 @@ func NewPropFromLDtk(entity *LDtkEntity) *Prop {
 	}
 
 	propType := entity.GetPropType()
+
+	damage := getDamageForType(propType)
+	if val := entity.GetCustomFieldValue("Damage"); val != nil {
+		// Level.Validate already made sure it fits
+		if d, ok := val.(float64); ok {
+			damage = int8(d)
+		}
+	}
+
 	width, height := getDimensionsForType(propType)
 	position := rl.NewVector2(entity.Px[0], entity.Px[1])
 	hitbox := getHitboxForType(propType, position)
 
+	// platforms, ladders and water are resized in the editor
+	if propType == PropPlatform || propType == PropLadder || propType == PropWater {
+		width, height = float32(entity.Width), float32(entity.Height)
+		hitbox = rl.NewRectangle(position.X, position.Y, width, height)
+	}
+
 	return &Prop{
-		Type:       propType,
-		Walkable:   walkable,
-		Pickable:   pickable,
-		Position:   position,
-		HitboxRect: hitbox,
-		Width:      width,
-		Height:     height,
+		Type:             propType,
+		Walkable:         walkable,
+		Pickable:         pickable,
+		Position:         position,
+		PreviousPosition: position,
+		HitboxRect:       hitbox,
+		Width:            width,
+		Height:           height,
+		Damage:           damage,
+		Animator:         NewAnimator(getClipForType(propType)),
 	}
 }
 
-func (entity *LDtkEntity) GetPropType() PropType {
-	types := map[string]PropType{
-		"Key":    PropKey,
-		"Door":   PropDoor,
-		"Spikes": PropSpikes,
-	}
+// Open swings a door open for good.
+func (prop *Prop) Open() {
+	prop.IsOpen = true
+	prop.Animator.Play("door_open")
+}
 
-	if propType, ok := types[entity.ID]; ok {
+// propTypes maps the LDtk entity identifiers to the props they become
+var propTypes = map[string]PropType{
+	"Key":      PropKey,
+	"Door":     PropDoor,
+	"Spikes":   PropSpikes,
+	"Platform": PropPlatform,
+	"Dash":     PropDash,
+	"Ladder":   PropLadder,
+	"Water":    PropWater,
+}
+
+func (entity *LDtkEntity) GetPropType() PropType {
+	if propType, ok := propTypes[entity.ID]; ok {
 		return propType
 	}
 
@@ -102,10 +135,13 @@ func (entity *LDtkEntity) GetCustomFieldValue(identifier string) any {
 
 func getDimensionsForType(propType PropType) (float32, float32) {
 	dimensions := map[PropType][2]float32{
-		PropKey:     {8, 8},
-		PropDoor:    {16, 16},
-		PropSpikes:  {8, 8},
-		PropGeneral: {8, 8},
+		PropKey:      {8, 8},
+		PropDoor:     {16, 16},
+		PropSpikes:   {8, 8},
+		PropPlatform: {24, 8},
+		PropDash:     {8, 8},
+		PropLadder:   {8, 32},
+		PropGeneral:  {8, 8},
 	}
 
 	if typeDimensions, ok := dimensions[propType]; ok {
@@ -115,6 +151,27 @@ func getDimensionsForType(propType PropType) (float32, float32) {
 	return dimensions[PropGeneral][0], dimensions[PropGeneral][1]
 }
 
+func getClipForType(propType PropType) string {
+	clips := map[PropType]string{
+		PropKey:      "key",
+		PropDoor:     "door_closed",
+		PropSpikes:   "spikes",
+		PropPlatform: "platform",
+		PropDash:     "dash",
+		PropLadder:   "ladder",
+	}
+
+	return clips[propType]
+}
+
+func getDamageForType(propType PropType) int8 {
+	damages := map[PropType]int8{
+		PropSpikes: SPIKES_DAMAGE,
+	}
+
+	return damages[propType]
+}
+
 func getHitboxForType(propType PropType, position rl.Vector2) rl.Rectangle {
 	width, height := getDimensionsForType(propType)
 
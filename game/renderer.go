@@ -2,6 +2,9 @@ package game
 
 import (
 	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"game3/assets"
+	"game3/collisions"
 )
 
 type Renderer struct {
@@ -9,6 +12,22 @@ type Renderer struct {
 	DebugMode bool
 }
 
+// NewRenderer uploads the textures to the GPU, so it needs an open window.
+func NewRenderer(debugMode bool) *Renderer {
+	// TODO: the keys from the map can probably be infered from the assets file data
+	tilemap := rl.LoadImageFromMemory(".png", assets.TILEMAP, int32(len(assets.TILEMAP)))
+	ground := rl.LoadImageFromMemory(".png", assets.GROUND, int32(len(assets.GROUND)))
+	var textures = map[string]rl.Texture2D{
+		"tilemap": rl.LoadTextureFromImage(tilemap),
+		"ground":  rl.LoadTextureFromImage(ground),
+	}
+
+	return &Renderer{
+		Textures:  textures,
+		DebugMode: debugMode,
+	}
+}
+
 func (r *Renderer) DrawSprite(textureID string, rec rl.Rectangle, position rl.Vector2) {
 	texture := r.Textures[textureID]
 	rl.DrawTextureRec(texture, rec, position, rl.White)
@@ -23,59 +42,97 @@ func (r *Renderer) DrawBackground(textureID string) {
 	rl.DrawTexture(texture, 0, 0, rl.White)
 }
 
+func (r *Renderer) DrawHitbox(hitbox rl.Rectangle, color rl.Color) {
+	rl.DrawRectangleLinesEx(hitbox, 1, color)
+}
+
+func (r *Renderer) DrawSlope(slope *collisions.Collider, color rl.Color) {
+	left := rl.NewVector2(slope.Rect.X, slope.GetSurfaceY(slope.Rect.X))
+	right := rl.NewVector2(slope.Rect.X+slope.Rect.Width, slope.GetSurfaceY(slope.Rect.X+slope.Rect.Width))
+
+	rl.DrawLineV(left, right, color)
+}
+
 func (r *Renderer) DrawParticle(particle *Particle) {
-	rl.DrawPixel(int32(particle.Position.X), int32(particle.Position.Y), rl.White)
+	color := rl.White
+	if particle.Type == SplashParticle {
+		color = rl.SkyBlue
+	}
+
+	rl.DrawPixel(int32(particle.Position.X), int32(particle.Position.Y), color)
 }
 
 func (r *Renderer) DrawProp(prop *Prop) {
-	tilemapPositionX, tilemapPositionY := getPropTilemapPosition(prop.Type, prop.IsOpen)
-	rec := rl.NewRectangle(tilemapPositionX, tilemapPositionY, prop.Width, prop.Height)
-	rl.DrawTextureRec(r.Textures["tilemap"], rec, prop.Position, rl.White)
+	r.DrawPropAt(prop, prop.Position)
+}
+
+func (r *Renderer) DrawPropAt(prop *Prop, position rl.Vector2) {
+	if prop.Type == PropPlatform {
+		r.DrawPlatform(prop)
+		return
+	}
+
+	if prop.Type == PropLadder {
+		r.DrawLadder(prop)
+		return
+	}
+
+	r.DrawAnimator(prop.Animator, position)
 
 	if r.DebugMode {
 		rl.DrawRectangleLines(int32(prop.HitboxRect.X), int32(prop.HitboxRect.Y), prop.HitboxRect.ToInt32().Width, prop.HitboxRect.ToInt32().Height, rl.Purple)
 	}
 }
 
-func (r *Renderer) DrawVFX(vfx *VFX) {
-	tilemapPositionX, tilemapPositionY := getVFXTilemapPosition(vfx.Type, vfx.AnimationCurrentPosition)
-	rec := rl.NewRectangle(tilemapPositionX, tilemapPositionY, 8, 8)
-	rl.DrawTextureRec(r.Textures["tilemap"], rec, vfx.Position, rl.White)
-}
+// DrawPlatform stretches a platform by repeating its middle tile between both ends.
+func (r *Renderer) DrawPlatform(prop *Prop) {
+	rec := prop.Animator.SourceRect()
+	tilemapPositionX, tilemapPositionY := rec.X, rec.Y
 
-func getPropTilemapPosition(propType PropType, isOpen bool) (float32, float32) {
-	positions := map[PropType][]float32{
-		PropKey:    {48, 32},
-		PropDoor:   {88, 48},
-		PropSpikes: {0, 40},
-	}
+	for offset := float32(0); offset < prop.Width; offset += TileSize {
+		tileX := tilemapPositionX + TileSize
 
-	if position, ok := positions[propType]; ok {
-		positionX := position[0]
-		positionY := position[1]
-
-		if isOpen && propType == PropDoor {
-			positionX += 16
+		if offset == 0 {
+			tileX = tilemapPositionX
+		} else if offset+TileSize >= prop.Width {
+			tileX = tilemapPositionX + 2*TileSize
 		}
 
-		return positionX, positionY
+		rec = rl.NewRectangle(tileX, tilemapPositionY, TileSize, TileSize)
+		rl.DrawTextureRec(r.Textures[prop.Animator.Texture()], rec, rl.NewVector2(prop.Position.X+offset, prop.Position.Y), rl.White)
 	}
 
-	return 0, 0
+	if r.DebugMode {
+		rl.DrawRectangleLines(int32(prop.HitboxRect.X), int32(prop.HitboxRect.Y), prop.HitboxRect.ToInt32().Width, prop.HitboxRect.ToInt32().Height, rl.Purple)
+	}
 }
 
-func getVFXTilemapPosition(vfxType VFXType, animationCurrentPosition int32) (float32, float32) {
-	positions := map[VFXType][]float32{
-		PlayerJumpVFX:  {120, 0},
-		PlayerDeathVFX: {56, 48},
-	}
+// DrawLadder repeats the ladder tile all the way down.
+func (r *Renderer) DrawLadder(prop *Prop) {
+	rec := prop.Animator.SourceRect()
+
+	for offset := float32(0); offset < prop.Height; offset += TileSize {
+		tileRec := rec
+		tileRec.Height = min(TileSize, prop.Height-offset)
 
-	if position, ok := positions[vfxType]; ok {
-		positionX := position[0] + (8 * float32(animationCurrentPosition))
-		positionY := position[1]
+		rl.DrawTextureRec(r.Textures[prop.Animator.Texture()], tileRec, rl.NewVector2(prop.Position.X, prop.Position.Y+offset), rl.White)
+	}
 
-		return positionX, positionY
+	if r.DebugMode {
+		rl.DrawRectangleLines(int32(prop.HitboxRect.X), int32(prop.HitboxRect.Y), prop.HitboxRect.ToInt32().Width, prop.HitboxRect.ToInt32().Height, rl.Purple)
 	}
+}
+
+func (r *Renderer) DrawWater(area rl.Rectangle) {
+	rl.DrawRectangleRec(area, rl.Fade(rl.SkyBlue, 0.45))
+	rl.DrawLineV(rl.NewVector2(area.X, area.Y), rl.NewVector2(area.X+area.Width, area.Y), rl.Fade(rl.White, 0.6))
+}
+
+func (r *Renderer) DrawVFX(vfx *VFX) {
+	r.DrawAnimator(vfx.Animator, vfx.Position)
+}
 
-	return 0, 0
+// DrawAnimator draws the current frame of the animator's clip.
+func (r *Renderer) DrawAnimator(animator *Animator, position rl.Vector2) {
+	rl.DrawTextureRec(r.Textures[animator.Texture()], animator.SourceRect(), position, rl.White)
 }
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"encoding/json"
+	"os"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"game3/input"
+)
+
+// Replay is everything needed to play a run again: where it started, the seed for the
+// random numbers, the movement profile and the actions of every simulated frame.
+type Replay struct {
+	Level           string              `json:"level"`
+	Position        rl.Vector2          `json:"position"`
+	Seed            int64               `json:"seed"`
+	CollisionSystem CollisionSystem     `json:"collisionSystem"`
+	Profile         *MovementProfile    `json:"profile,omitempty"`
+	Transition      *TransitionSettings `json:"transition,omitempty"`
+	Frames          []input.State       `json:"frames"`
+	cursor          int
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var replay Replay
+	if err := json.Unmarshal(data, &replay); err != nil {
+		return nil, err
+	}
+
+	return &replay, nil
+}
+
+func (r *Replay) Save(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+func (r *Replay) Record(actions input.State) {
+	r.Frames = append(r.Frames, actions)
+}
+
+// Next returns the actions of the following frame, false once the replay is over.
+func (r *Replay) Next() (input.State, bool) {
+	if r.cursor >= len(r.Frames) {
+		return input.State{}, false
+	}
+
+	actions := r.Frames[r.cursor]
+	r.cursor++
+
+	return actions, true
+}
@@ -0,0 +1,60 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"game3/input"
+)
+
+func TestReplayRoundTrip(t *testing.T) {
+	g := NewGame(RegularCollision, 7)
+	g.StartRecording()
+
+	script := []input.State{holding(input.MoveRight), pressing(input.Jump), pressing(input.Dash), pressing(input.Reset)}
+	for _, actions := range script {
+		g.Tick(FIXED_DELTA, actions)
+	}
+
+	path := filepath.Join(t.TempDir(), "run.replay")
+	if err := g.Recording.Save(path); err != nil {
+		t.Fatalf("error saving the replay: %s", err)
+	}
+
+	replay, err := LoadReplay(path)
+	if err != nil {
+		t.Fatalf("error loading the replay: %s", err)
+	}
+
+	if replay.Seed != 7 || replay.Level != g.Recording.Level || !slices.Equal(replay.Frames, g.Recording.Frames) {
+		t.Fatalf("expected the replay to load as it was saved, got %+v", replay)
+	}
+}
+
+// replays store the bits of the actions, adding an action must not move the others
+func TestOldReplaysKeepTheirActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.replay")
+	old := `{"level":"Level_4","seed":1,"frames":[{"held":17},{"pressed":64},{"released":512}]}`
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatalf("error writing the replay: %s", err)
+	}
+
+	replay, err := LoadReplay(path)
+	if err != nil {
+		t.Fatalf("error loading the replay: %s", err)
+	}
+
+	if !replay.Frames[0].IsHeld(input.MoveLeft) || !replay.Frames[0].IsHeld(input.Jump) {
+		t.Fatalf("expected move_left and jump held on the first frame")
+	}
+
+	if !replay.Frames[1].IsPressed(input.Reset) || replay.Frames[1].IsPressed(input.Dash) {
+		t.Fatalf("expected reset pressed on the second frame")
+	}
+
+	if !replay.Frames[2].IsReleased(input.StepFrame) {
+		t.Fatalf("expected step_frame released on the third frame")
+	}
+}
@@ -0,0 +1,40 @@
+package game
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type SoundEffect int
+
+const (
+	JumpSound SoundEffect = iota
+	LandSound
+	DashSound
+	HurtSound
+	DeathSound
+	StepSound
+	SplashSound
+)
+
+var soundEffectName = map[SoundEffect]string{
+	JumpSound:   "jump",
+	LandSound:   "land",
+	DashSound:   "dash",
+	HurtSound:   "hurt",
+	DeathSound:  "death",
+	StepSound:   "step",
+	SplashSound: "splash",
+}
+
+func (se SoundEffect) String() string {
+	return soundEffectName[se]
+}
+
+// PlaySound is the single place sound effects go through. Nothing is audible yet, there are
+// no audio assets, so for now the effects are only logged in debug mode.
+// TODO: load the sounds in the renderer's fashion and play them here
+func (game *Game) PlaySound(effect SoundEffect) {
+	if game.DebugMode {
+		rl.TraceLog(rl.LogDebug, "sound: %s", effect)
+	}
+}
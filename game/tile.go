@@ -5,6 +5,8 @@ import (
 )
 
 type Tile struct {
+	ID             int
+	Tileset        string
 	Position       rl.Vector2
 	SpritePosition rl.Vector2
 	HitboxRect     rl.Rectangle
@@ -16,18 +18,45 @@ type LDtkTile struct {
 	Src []float32
 }
 
+// SlopeShape holds the height of the surface of a slope tile at its left and right edges,
+// measured from the bottom of the tile.
+type SlopeShape struct {
+	LeftHeight  float32
+	RightHeight float32
+}
+
 const (
 	TileSize    = 8
 	TilesPerRow = 17
 )
 
-func NewTileFromLDtk(ldtkTile *LDtkTile) *Tile {
+// slope tiles of every tileset, keyed by tile id
+var slopeTiles = map[string]map[int]SlopeShape{
+	"ground": {
+		132: {0, 8}, // 45° up
+		166: {8, 0}, // 45° down
+		188: {0, 4}, // 22.5° up, lower half
+		189: {4, 8}, // 22.5° up, upper half
+		190: {8, 4}, // 22.5° down, upper half
+		191: {4, 0}, // 22.5° down, lower half
+	},
+}
+
+func NewTileFromLDtk(ldtkTile *LDtkTile, tileset string) *Tile {
 	positionX := float32(ldtkTile.Px[0])
 	positionY := float32(ldtkTile.Px[1])
 
 	return &Tile{
+		ID:             ldtkTile.T,
+		Tileset:        tileset,
 		Position:       rl.NewVector2(positionX, positionY),
 		SpritePosition: rl.NewVector2(ldtkTile.Src[0], ldtkTile.Src[1]),
 		HitboxRect:     rl.NewRectangle(positionX, positionY, TileSize, TileSize),
 	}
 }
+
+func (t *Tile) GetSlopeShape() (SlopeShape, bool) {
+	shape, ok := slopeTiles[t.Tileset][t.ID]
+
+	return shape, ok
+}
@@ -0,0 +1,118 @@
+package game
+
+import (
+	"fmt"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type TransitionType int
+
+const (
+	CutTransition TransitionType = iota
+	SlideTransition
+	FadeTransition
+)
+
+const ROOM_TRANSITION_FRAMES int32 = SIMULATION_FPS * 2 / 5
+
+var transitionTypeName = map[TransitionType]string{
+	CutTransition:   "cut",
+	SlideTransition: "slide",
+	FadeTransition:  "fade",
+}
+
+func (tt TransitionType) String() string {
+	return transitionTypeName[tt]
+}
+
+func ParseTransitionType(name string) (TransitionType, error) {
+	for transitionType, transitionName := range transitionTypeName {
+		if transitionName == name {
+			return transitionType, nil
+		}
+	}
+
+	return CutTransition, fmt.Errorf("unknown room transition %q, available: cut, slide, fade", name)
+}
+
+// TransitionSettings is how moving between rooms looks, replays keep the one they were
+// recorded with since the player is frozen while it plays.
+type TransitionSettings struct {
+	Type   TransitionType `json:"type"`
+	Frames int32          `json:"frames"`
+}
+
+func DefaultTransitionSettings() TransitionSettings {
+	return TransitionSettings{
+		Type:   SlideTransition,
+		Frames: ROOM_TRANSITION_FRAMES,
+	}
+}
+
+// RoomTransition goes from the room the player left to the one it entered. Everything is
+// kept in the space of the new room, the previous one sitting at Offset.
+type RoomTransition struct {
+	Type       TransitionType
+	From       *Level
+	Offset     rl.Vector2
+	FromCamera rl.Vector2
+	ToCamera   rl.Vector2
+	Frames     int32
+	Duration   int32
+}
+
+// Tick moves the transition one step, placing the camera where it should be by then.
+func (rt *RoomTransition) Tick(camera *Camera) {
+	rt.Frames++
+	camera.PreviousPosition = camera.Position
+	camera.Position = rt.CameraPosition()
+}
+
+func (rt *RoomTransition) IsDone() bool {
+	return rt.Frames >= rt.Duration
+}
+
+func (rt *RoomTransition) Progress() float32 {
+	return rl.Clamp(float32(rt.Frames)/float32(rt.Duration), 0, 1)
+}
+
+// CameraPosition slides between both rooms, a fade cuts over halfway, while the screen is
+// black.
+func (rt *RoomTransition) CameraPosition() rl.Vector2 {
+	progress := rt.Progress()
+
+	if rt.Type == FadeTransition {
+		if progress < 0.5 {
+			return rt.FromCamera
+		}
+
+		return rt.ToCamera
+	}
+
+	// smoothstep, easing in and out of the slide
+	eased := progress * progress * (3 - 2*progress)
+
+	return rl.Vector2Lerp(rt.FromCamera, rt.ToCamera, eased)
+}
+
+// DrawPreviousRoom draws the room left behind where it sits next to the new one.
+func (rt *RoomTransition) DrawPreviousRoom(r *Renderer, camera rl.Camera2D) {
+	camera.Target = rl.Vector2Subtract(camera.Target, rt.Offset)
+
+	rl.BeginMode2D(camera)
+	rt.From.Draw(r)
+	rl.EndMode2D()
+}
+
+// DrawOverlay darkens the screen for fades, all the way to black halfway through.
+func (rt *RoomTransition) DrawOverlay(alpha float32) {
+	if rt.Type != FadeTransition {
+		return
+	}
+
+	progress := rl.Clamp((float32(rt.Frames)+alpha)/float32(rt.Duration), 0, 1)
+	opacity := 1 - 2*max(progress-0.5, 0.5-progress)
+
+	rl.DrawRectangle(0, 0, int32(VIEWPORT_WIDTH), int32(VIEWPORT_HEIGHT), rl.Fade(rl.Black, opacity))
+}
@@ -9,40 +9,36 @@ type VFXType int
 const (
 	PlayerJumpVFX VFXType = iota
 	PlayerDeathVFX
+	PlayerWallJumpVFX
+	PlayerDashVFX
+	PlayerLandVFX
+	SplashVFX
 )
 
+var vfxClips = map[VFXType]string{
+	PlayerJumpVFX:     "vfx_jump",
+	PlayerDeathVFX:    "vfx_death",
+	PlayerWallJumpVFX: "vfx_jump",
+	PlayerDashVFX:     "vfx_dash",
+	PlayerLandVFX:     "vfx_jump",
+	SplashVFX:         "vfx_splash",
+}
+
 type VFX struct {
-	Type                       VFXType
-	Position                   rl.Vector2
-	AnimationCurrentFrame      int32
-	AnimationCurrentPosition   int32
-	AnimationFramesPerPosition int32
-	AnimationFramesCounter     int32
-	AnimationPositionsCounter  int32
-	Loops                      bool
+	Type     VFXType
+	Position rl.Vector2
+	Animator *Animator
 }
 
 func NewVFX(vfxType VFXType, position rl.Vector2) VFX {
-	inventory := map[VFXType]VFX{
-		PlayerJumpVFX: {
-			Type:                       PlayerJumpVFX,
-			Position:                   position,
-			AnimationFramesPerPosition: 4,
-			AnimationPositionsCounter:  4,
-			Loops:                      false,
-		},
-		PlayerDeathVFX: {
-			Type:                       PlayerDeathVFX,
-			Position:                   position,
-			AnimationFramesPerPosition: 10,
-			AnimationPositionsCounter:  3,
-			Loops:                      false,
-		},
-	}
-
-	if vfx, ok := inventory[vfxType]; ok {
-		return vfx
+	return VFX{
+		Type:     vfxType,
+		Position: position,
+		Animator: NewAnimator(vfxClips[vfxType]),
 	}
+}
 
-	return VFX{}
+// IsDone tells whether a one-shot effect went through all its frames.
+func (vfx *VFX) IsDone() bool {
+	return vfx.Animator.Clip == nil || vfx.Animator.Finished
 }
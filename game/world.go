@@ -0,0 +1,259 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"game3/levels"
+)
+
+type World struct {
+	ID     string   `json:"iid"`
+	Levels []*Level `json:"levels"`
+}
+
+type WorldErrorKind int
+
+const (
+	MalformedWorld WorldErrorKind = iota
+	MissingLayer
+	UnknownEntity
+	MalformedEntity
+	BrokenNeighbour
+	MalformedTile
+	UnknownLevel
+	MalformedLayer
+)
+
+var worldErrorKindName = map[WorldErrorKind]string{
+	MalformedWorld:  "malformed world",
+	MissingLayer:    "missing layer",
+	UnknownEntity:   "unknown entity",
+	MalformedEntity: "malformed entity",
+	BrokenNeighbour: "broken neighbour",
+	MalformedTile:   "malformed tile",
+	UnknownLevel:    "unknown level",
+	MalformedLayer:  "malformed layer",
+}
+
+func (k WorldErrorKind) String() string {
+	return worldErrorKindName[k]
+}
+
+// WorldError points at what is wrong in the LDtk world and where. Level and Layer are
+// empty when the problem is not tied to one.
+type WorldError struct {
+	Kind   WorldErrorKind
+	Level  string
+	Layer  string
+	Detail string
+	Err    error
+}
+
+func (e *WorldError) Error() string {
+	message := e.Kind.String()
+
+	if e.Layer != "" {
+		message = fmt.Sprintf("layer %s: %s", e.Layer, message)
+	}
+
+	if e.Level != "" {
+		message = fmt.Sprintf("%s: %s", e.Level, message)
+	}
+
+	if e.Detail != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Detail)
+	}
+
+	if e.Err != nil {
+		message = fmt.Sprintf("%s: %s", message, e.Err.Error())
+	}
+
+	return message
+}
+
+func (e *WorldError) Unwrap() error {
+	return e.Err
+}
+
+// tile layers every level has to have, Entities is optional
+var requiredLayers = []string{"Background", "BackgroundProps", "Ground", "ForegroundProps"}
+
+var neighbourDirections = []string{"n", "s", "e", "w", "ne", "nw", "se", "sw", "<", ">", "o"}
+
+// LoadWorld reads the world embedded in the binary.
+func LoadWorld() (*World, error) {
+	return ParseWorld(levels.LEVELS)
+}
+
+func LoadWorldFromFile(path string) (*World, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseWorld(data)
+}
+
+// ParseWorld decodes an LDtk project and validates it. Every problem found is reported,
+// joined in a single error whose parts are *WorldError.
+func ParseWorld(data []byte) (*World, error) {
+	var world World
+	if err := json.Unmarshal(data, &world); err != nil {
+		return nil, &WorldError{Kind: MalformedWorld, Err: err}
+	}
+
+	if err := world.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &world, nil
+}
+
+func (w *World) Validate() error {
+	var errs []error
+
+	if len(w.Levels) == 0 {
+		errs = append(errs, &WorldError{Kind: MalformedWorld, Detail: "no levels"})
+	}
+
+	for _, level := range w.Levels {
+		errs = append(errs, level.Validate()...)
+
+		for _, neighbour := range level.Neighbours {
+			if w.FindLevelByID(neighbour.LevelID) == nil {
+				errs = append(errs, &WorldError{Kind: BrokenNeighbour, Level: level.Name, Detail: fmt.Sprintf("no level with iid %q", neighbour.LevelID)})
+			}
+
+			if !slices.Contains(neighbourDirections, neighbour.Direction) {
+				errs = append(errs, &WorldError{Kind: BrokenNeighbour, Level: level.Name, Detail: fmt.Sprintf("unknown direction %q", neighbour.Direction)})
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (w *World) FindLevel(name string) *Level {
+	for _, level := range w.Levels {
+		if level.Name == name {
+			return level
+		}
+	}
+
+	return nil
+}
+
+func (w *World) FindLevelByID(levelID string) *Level {
+	for _, level := range w.Levels {
+		if level.ID == levelID {
+			return level
+		}
+	}
+
+	return nil
+}
+
+// FindNeighbourAt returns the neighbour of the level covering a point in world coordinates.
+func (w *World) FindNeighbourAt(level *Level, point rl.Vector2) *Level {
+	for _, neighbour := range level.Neighbours {
+		candidate := w.FindLevelByID(neighbour.LevelID)
+		if candidate != nil && rl.CheckCollisionPointRec(point, candidate.WorldRect()) {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+// Validate checks everything Load relies on, so a level that validates always loads.
+func (l *Level) Validate() []error {
+	var errs []error
+
+	for _, layerName := range requiredLayers {
+		layer := l.GetLayer(layerName)
+		if layer == nil {
+			errs = append(errs, &WorldError{Kind: MissingLayer, Level: l.Name, Layer: layerName})
+			continue
+		}
+
+		for i, tile := range layer.RawLayout {
+			if detail := l.validateTile(tile); detail != "" {
+				errs = append(errs, &WorldError{Kind: MalformedTile, Level: l.Name, Layer: layerName, Detail: fmt.Sprintf("tile %d %s", i, detail)})
+			}
+		}
+	}
+
+	if collision := l.GetLayer("Collision"); collision != nil {
+		if detail := validateIntGrid(collision); detail != "" {
+			errs = append(errs, &WorldError{Kind: MalformedLayer, Level: l.Name, Layer: collision.Name, Detail: detail})
+		}
+	}
+
+	if entities := l.GetEntitiesLayer(); entities != nil {
+		for _, entity := range entities.RawEntities {
+			if _, ok := propTypes[entity.ID]; !ok {
+				errs = append(errs, &WorldError{Kind: UnknownEntity, Level: l.Name, Layer: entities.Name, Detail: entity.ID})
+			}
+
+			if len(entity.Px) != 2 {
+				errs = append(errs, &WorldError{Kind: MalformedEntity, Level: l.Name, Layer: entities.Name, Detail: fmt.Sprintf("%s has no position", entity.ID)})
+			}
+
+			// damage is kept in an int8, anything else would wrap around
+			if damage, ok := entity.GetCustomFieldValue("Damage").(float64); ok && (damage < 0 || damage > math.MaxInt8) {
+				errs = append(errs, &WorldError{Kind: MalformedEntity, Level: l.Name, Layer: entities.Name, Detail: fmt.Sprintf("%s has a damage of %v, out of 0..%d", entity.ID, damage, math.MaxInt8)})
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateIntGrid(layer *LevelLayer) string {
+	if layer.Type != "IntGrid" {
+		return fmt.Sprintf("is a %s layer instead of an IntGrid one", layer.Type)
+	}
+
+	if layer.GridSize != TileSize {
+		return fmt.Sprintf("has a grid of %dpx instead of %dpx", layer.GridSize, TileSize)
+	}
+
+	if len(layer.IntGrid) != layer.Columns*layer.Rows {
+		return fmt.Sprintf("has %d cells for a %dx%d grid", len(layer.IntGrid), layer.Columns, layer.Rows)
+	}
+
+	for i, value := range layer.IntGrid {
+		if value < int(EmptyCell) || value > int(WaterCell) {
+			return fmt.Sprintf("cell %d has an unknown value %d", i, value)
+		}
+	}
+
+	return ""
+}
+
+func (l *Level) validateTile(tile *LDtkTile) string {
+	if tile == nil {
+		return "is empty"
+	}
+
+	if len(tile.Px) != 2 || len(tile.Src) != 2 {
+		return "has no position or source"
+	}
+
+	if tile.T < 0 {
+		return fmt.Sprintf("has an invalid id %d", tile.T)
+	}
+
+	if tile.Px[0] < 0 || tile.Px[1] < 0 || tile.Px[0] >= float32(l.Width) || tile.Px[1] >= float32(l.Height) {
+		return fmt.Sprintf("at %v is out of the level", tile.Px)
+	}
+
+	return ""
+}
@@ -0,0 +1,150 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+
+	"game3/levels"
+)
+
+func TestEmbeddedWorldIsValid(t *testing.T) {
+	if _, err := LoadWorld(); err != nil {
+		t.Fatalf("expected the embedded world to validate, got:\n%s", err)
+	}
+}
+
+// brokenWorld returns the embedded world with a problem of every kind in it.
+func brokenWorld(t *testing.T) []byte {
+	var project map[string]any
+	if err := json.Unmarshal(levels.LEVELS, &project); err != nil {
+		t.Fatalf("error decoding the world: %s", err)
+	}
+
+	worldLevels := project["levels"].([]any)
+	level := worldLevels[0].(map[string]any)
+
+	var layers []any
+	for _, layer := range level["layerInstances"].([]any) {
+		layer := layer.(map[string]any)
+
+		switch layer["__identifier"] {
+		case "Ground":
+			continue
+		case "Background":
+			tile := layer["gridTiles"].([]any)[0].(map[string]any)
+			tile["px"] = []any{-8, 0}
+		case "Collision":
+			layer["intGridCsv"] = []any{0, 9}
+		case "Entities":
+			entity := layer["entityInstances"].([]any)[0].(map[string]any)
+			entity["__identifier"] = "Dragon"
+		}
+
+		layers = append(layers, layer)
+	}
+	level["layerInstances"] = layers
+
+	neighbour := level["__neighbours"].([]any)[0].(map[string]any)
+	neighbour["levelIid"] = "missing"
+
+	data, err := json.Marshal(project)
+	if err != nil {
+		t.Fatalf("error encoding the world: %s", err)
+	}
+
+	return data
+}
+
+func TestBrokenWorldReportsEveryProblem(t *testing.T) {
+	_, err := ParseWorld(brokenWorld(t))
+	if err == nil {
+		t.Fatalf("expected the broken world not to validate")
+	}
+
+	found := map[WorldErrorKind]bool{}
+	for _, problem := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var worldError *WorldError
+		if !errors.As(problem, &worldError) {
+			t.Fatalf("expected structured errors, got %q", problem)
+		}
+
+		found[worldError.Kind] = true
+	}
+
+	for _, kind := range []WorldErrorKind{MissingLayer, MalformedTile, UnknownEntity, BrokenNeighbour, MalformedLayer} {
+		if !found[kind] {
+			t.Errorf("expected a %s error, got:\n%s", kind, err)
+		}
+	}
+}
+
+func TestLoadingUnknownLevel(t *testing.T) {
+	g := NewGame(RegularCollision, 1)
+
+	var worldError *WorldError
+	if err := g.LoadLevel("Level_99"); !errors.As(err, &worldError) || worldError.Kind != UnknownLevel {
+		t.Fatalf("expected an unknown level error, got %v", err)
+	}
+
+	if g.CurrentLevel.Name != "Level_4" {
+		t.Fatalf("expected the current level to stay, got %s", g.CurrentLevel.Name)
+	}
+}
+
+func TestNeighbourIsPickedByExitPoint(t *testing.T) {
+	// a tall level with two rooms stacked against its east side
+	west := &Level{ID: "west", WorldX: 0, WorldY: 0, Width: 320, Height: 360}
+	upper := &Level{ID: "upper", WorldX: 320, WorldY: 0, Width: 320, Height: 180}
+	lower := &Level{ID: "lower", WorldX: 320, WorldY: 180, Width: 320, Height: 180}
+	west.Neighbours = []*LevelNeighbour{{LevelID: "upper", Direction: "e"}, {LevelID: "lower", Direction: "e"}}
+	world := &World{Levels: []*Level{west, upper, lower}}
+
+	if next := world.FindNeighbourAt(west, west.ToWorld(rl.NewVector2(322, 40))); next != upper {
+		t.Fatalf("expected the upper room, got %v", next)
+	}
+
+	if next := world.FindNeighbourAt(west, west.ToWorld(rl.NewVector2(322, 300))); next != lower {
+		t.Fatalf("expected the lower room, got %v", next)
+	}
+
+	if next := world.FindNeighbourAt(west, west.ToWorld(rl.NewVector2(-2, 40))); next != nil {
+		t.Fatalf("expected nothing west of the level, got %s", next.ID)
+	}
+
+	if local := lower.ToLocal(west.ToWorld(rl.NewVector2(322, 300))); local != rl.NewVector2(2, 120) {
+		t.Fatalf("expected the exit point in the lower room's space, got %v", local)
+	}
+}
+
+func TestOutOfRangeDamageIsRejected(t *testing.T) {
+	var project map[string]any
+	if err := json.Unmarshal(levels.LEVELS, &project); err != nil {
+		t.Fatalf("error decoding the world: %s", err)
+	}
+
+	for _, level := range project["levels"].([]any) {
+		for _, layer := range level.(map[string]any)["layerInstances"].([]any) {
+			for _, entity := range layer.(map[string]any)["entityInstances"].([]any) {
+				for _, field := range entity.(map[string]any)["fieldInstances"].([]any) {
+					if field := field.(map[string]any); field["__identifier"] == "Damage" {
+						field["__value"] = 200
+					}
+				}
+			}
+		}
+	}
+
+	data, err := json.Marshal(project)
+	if err != nil {
+		t.Fatalf("error encoding the world: %s", err)
+	}
+
+	_, err = ParseWorld(data)
+	var worldError *WorldError
+	if !errors.As(err, &worldError) || worldError.Kind != MalformedEntity {
+		t.Fatalf("expected a malformed entity error for a damage of 200, got %v", err)
+	}
+}
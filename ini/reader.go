@@ -0,0 +1,55 @@
+package ini
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Line is a line of an ini file that is not blank nor a comment. Section headers come with
+// IsSection set and the new section in Section, the other lines with the section they are in,
+// empty before the first header.
+type Line struct {
+	Number    int
+	Section   string
+	IsSection bool
+	Name      string
+	Value     string
+	HasValue  bool // false when the line has no "="
+}
+
+// Read calls read for every line of the file, stopping at the first error it returns.
+// Errors are prefixed with the line they happened on.
+func Read(r io.Reader, read func(line Line) error) error {
+	var section string
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		line := Line{Number: lineNumber}
+
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			line.IsSection = true
+		} else {
+			name, value, found := strings.Cut(text, "=")
+			line.Name = strings.TrimSpace(name)
+			line.Value = strings.TrimSpace(value)
+			line.HasValue = found
+		}
+
+		line.Section = section
+
+		if err := read(line); err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+
+	return scanner.Err()
+}
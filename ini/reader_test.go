@@ -0,0 +1,51 @@
+package ini
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	var lines []Line
+	err := Read(strings.NewReader("# comment\nbase = 1\n\n[first]\n; another comment\n  name =  value  \nbroken\n"), func(line Line) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error reading: %s", err.Error())
+	}
+
+	expected := []Line{
+		{Number: 2, Name: "base", Value: "1", HasValue: true},
+		{Number: 4, Section: "first", IsSection: true},
+		{Number: 6, Section: "first", Name: "name", Value: "value", HasValue: true},
+		{Number: 7, Section: "first", Name: "broken"},
+	}
+
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %+v", len(expected), lines)
+	}
+
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], lines[i])
+		}
+	}
+}
+
+func TestReadStopsAtTheFirstError(t *testing.T) {
+	var read int
+	err := Read(strings.NewReader("a = 1\nb = 2\nc = 3\n"), func(line Line) error {
+		read++
+		if line.Name == "b" {
+			return errors.New("bad value")
+		}
+
+		return nil
+	})
+
+	if err == nil || err.Error() != "line 2: bad value" || read != 2 {
+		t.Fatalf("expected to stop at line 2, got %v after %d lines", err, read)
+	}
+}
@@ -0,0 +1,110 @@
+package input
+
+type Action int
+
+const (
+	MoveLeft Action = iota
+	MoveRight
+	MoveUp
+	MoveDown
+	Jump
+	Interact
+	Reset
+	Pause
+	ToggleInspector
+	StepFrame
+	// new actions go last, replays store the bit of every action
+	Dash
+)
+
+var actionName = map[Action]string{
+	MoveLeft:        "move_left",
+	MoveRight:       "move_right",
+	MoveUp:          "move_up",
+	MoveDown:        "move_down",
+	Jump:            "jump",
+	Interact:        "interact",
+	Reset:           "reset",
+	Pause:           "pause",
+	ToggleInspector: "toggle_inspector",
+	StepFrame:       "step_frame",
+	Dash:            "dash",
+}
+
+func (a Action) String() string {
+	return actionName[a]
+}
+
+func (a Action) bit() uint32 {
+	return 1 << uint32(a)
+}
+
+// Actions lists every action in declaration order.
+func Actions() []Action {
+	actions := make([]Action, 0, len(actionName))
+	for action := range Action(len(actionName)) {
+		actions = append(actions, action)
+	}
+
+	return actions
+}
+
+func ParseAction(name string) (Action, bool) {
+	for action, actionName := range actionName {
+		if actionName == name {
+			return action, true
+		}
+	}
+
+	return 0, false
+}
+
+// State holds which actions are down during a frame, and which of them started or
+// stopped being down on that very frame.
+type State struct {
+	Held     uint32 `json:"held,omitempty"`
+	Pressed  uint32 `json:"pressed,omitempty"`
+	Released uint32 `json:"released,omitempty"`
+}
+
+func (s State) IsHeld(action Action) bool {
+	return s.Held&action.bit() != 0
+}
+
+func (s State) IsPressed(action Action) bool {
+	return s.Pressed&action.bit() != 0
+}
+
+func (s State) IsReleased(action Action) bool {
+	return s.Released&action.bit() != 0
+}
+
+func (s *State) Set(action Action, held bool, pressed bool, released bool) {
+	if held {
+		s.Held |= action.bit()
+	}
+
+	if pressed {
+		s.Pressed |= action.bit()
+	}
+
+	if released {
+		s.Released |= action.bit()
+	}
+}
+
+// WithoutEdges keeps what is held, the presses and releases only belong to the first
+// simulation step of a frame.
+func (s State) WithoutEdges() State {
+	return State{Held: s.Held}
+}
+
+// Merge carries the presses and releases of a state that was never simulated over into
+// the next one, so they are not lost while the simulation is paused or on frames too
+// short to run a step.
+func (s State) Merge(pending State) State {
+	s.Pressed |= pending.Pressed
+	s.Released |= pending.Released
+
+	return s
+}
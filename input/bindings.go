@@ -0,0 +1,255 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"game3/ini"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const DEFAULT_CONTROLS_PATH string = "controls.ini"
+
+type Binding struct {
+	Keys           []int32
+	GamepadButtons []int32
+}
+
+type Bindings map[Action]*Binding
+
+func DefaultBindings() Bindings {
+	return Bindings{
+		MoveLeft:        {Keys: []int32{rl.KeyA}, GamepadButtons: []int32{rl.GamepadButtonLeftFaceLeft}},
+		MoveRight:       {Keys: []int32{rl.KeyD}, GamepadButtons: []int32{rl.GamepadButtonLeftFaceRight}},
+		MoveUp:          {Keys: []int32{rl.KeyW}, GamepadButtons: []int32{rl.GamepadButtonLeftFaceUp}},
+		MoveDown:        {Keys: []int32{rl.KeyS}, GamepadButtons: []int32{rl.GamepadButtonLeftFaceDown}},
+		Jump:            {Keys: []int32{rl.KeySpace}, GamepadButtons: []int32{rl.GamepadButtonRightFaceDown}},
+		Interact:        {Keys: []int32{rl.KeyE}, GamepadButtons: []int32{rl.GamepadButtonRightFaceLeft}},
+		Dash:            {Keys: []int32{rl.KeyLeftShift}, GamepadButtons: []int32{rl.GamepadButtonRightFaceRight}},
+		Reset:           {Keys: []int32{rl.KeyR}, GamepadButtons: []int32{rl.GamepadButtonMiddleLeft}},
+		Pause:           {Keys: []int32{rl.KeyEscape}, GamepadButtons: []int32{rl.GamepadButtonMiddleRight}},
+		ToggleInspector: {Keys: []int32{rl.KeyI}},
+		StepFrame:       {Keys: []int32{rl.KeyN}},
+	}
+}
+
+// LoadBindings reads the bindings from an ini file, any action missing from it keeps
+// its default binding. A missing file is not an error, the defaults are used instead.
+func LoadBindings(path string) (Bindings, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return DefaultBindings(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseBindings(file)
+}
+
+// ParseBindings reads a [keyboard] and a [gamepad] section, where each line binds an
+// action to a comma separated list of key or button names:
+//
+//	[keyboard]
+//	jump = Space, Up
+func ParseBindings(r io.Reader) (Bindings, error) {
+	bindings := DefaultBindings()
+	overridden := map[string]bool{}
+
+	err := ini.Read(r, func(line ini.Line) error {
+		if line.IsSection {
+			if line.Section != "keyboard" && line.Section != "gamepad" {
+				return fmt.Errorf("unknown section %q", line.Section)
+			}
+
+			return nil
+		}
+
+		if !line.HasValue {
+			return errors.New("expected \"action = keys\"")
+		}
+
+		if line.Section == "" {
+			return errors.New("binding outside of a section")
+		}
+
+		action, ok := ParseAction(line.Name)
+		if !ok {
+			return fmt.Errorf("unknown action %q", line.Name)
+		}
+
+		var codes []int32
+		for _, codeName := range strings.Split(line.Value, ",") {
+			codeName = strings.TrimSpace(codeName)
+			if codeName == "" {
+				continue
+			}
+
+			code, ok := parseCode(line.Section, codeName)
+			if !ok {
+				return fmt.Errorf("unknown %s input %q", line.Section, codeName)
+			}
+
+			codes = append(codes, code)
+		}
+
+		// the first time an action shows up in a section its defaults are dropped
+		key := line.Section + "." + action.String()
+		if !overridden[key] {
+			overridden[key] = true
+
+			if line.Section == "keyboard" {
+				bindings[action].Keys = nil
+			} else {
+				bindings[action].GamepadButtons = nil
+			}
+		}
+
+		// repeating a key on another line binds it only once
+		if line.Section == "keyboard" {
+			bindings[action].Keys = appendMissing(bindings[action].Keys, codes)
+		} else {
+			bindings[action].GamepadButtons = appendMissing(bindings[action].GamepadButtons, codes)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bindings, nil
+}
+
+func (b Bindings) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return b.Write(file)
+}
+
+func (b Bindings) Write(w io.Writer) error {
+	var builder strings.Builder
+
+	builder.WriteString("[keyboard]\n")
+	for _, action := range Actions() {
+		if binding, ok := b[action]; ok {
+			fmt.Fprintln(&builder, strings.TrimSpace(fmt.Sprintf("%s = %s", action, binding.FormatKeys())))
+		}
+	}
+
+	builder.WriteString("\n[gamepad]\n")
+	for _, action := range Actions() {
+		if binding, ok := b[action]; ok {
+			fmt.Fprintln(&builder, strings.TrimSpace(fmt.Sprintf("%s = %s", action, binding.FormatGamepadButtons())))
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+
+	return err
+}
+
+func appendMissing(codes []int32, added []int32) []int32 {
+	for _, code := range added {
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
+
+func (b *Binding) FormatKeys() string {
+	return formatCodes(keyNames, b.Keys)
+}
+
+func (b *Binding) FormatGamepadButtons() string {
+	return formatCodes(gamepadButtonNames, b.GamepadButtons)
+}
+
+func parseCode(section string, name string) (int32, bool) {
+	names := keyNames
+	if section == "gamepad" {
+		names = gamepadButtonNames
+	}
+
+	for code, codeName := range names {
+		if strings.EqualFold(codeName, name) {
+			return code, true
+		}
+	}
+
+	return 0, false
+}
+
+func formatCodes(names map[int32]string, codes []int32) string {
+	formatted := make([]string, 0, len(codes))
+	for _, code := range codes {
+		formatted = append(formatted, names[code])
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+var keyNames = func() map[int32]string {
+	names := map[int32]string{
+		rl.KeySpace:        "Space",
+		rl.KeyEscape:       "Escape",
+		rl.KeyEnter:        "Enter",
+		rl.KeyTab:          "Tab",
+		rl.KeyBackspace:    "Backspace",
+		rl.KeyUp:           "Up",
+		rl.KeyDown:         "Down",
+		rl.KeyLeft:         "Left",
+		rl.KeyRight:        "Right",
+		rl.KeyLeftShift:    "LeftShift",
+		rl.KeyRightShift:   "RightShift",
+		rl.KeyLeftControl:  "LeftControl",
+		rl.KeyRightControl: "RightControl",
+		rl.KeyLeftAlt:      "LeftAlt",
+		rl.KeyRightAlt:     "RightAlt",
+	}
+
+	for key := int32(rl.KeyA); key <= rl.KeyZ; key++ {
+		names[key] = string(rune(key))
+	}
+
+	for key := int32(rl.KeyZero); key <= rl.KeyNine; key++ {
+		names[key] = string(rune(key))
+	}
+
+	for key := int32(rl.KeyF1); key <= rl.KeyF12; key++ {
+		names[key] = fmt.Sprintf("F%d", key-rl.KeyF1+1)
+	}
+
+	return names
+}()
+
+var gamepadButtonNames = map[int32]string{
+	rl.GamepadButtonLeftFaceUp:     "LeftFaceUp",
+	rl.GamepadButtonLeftFaceRight:  "LeftFaceRight",
+	rl.GamepadButtonLeftFaceDown:   "LeftFaceDown",
+	rl.GamepadButtonLeftFaceLeft:   "LeftFaceLeft",
+	rl.GamepadButtonRightFaceUp:    "RightFaceUp",
+	rl.GamepadButtonRightFaceRight: "RightFaceRight",
+	rl.GamepadButtonRightFaceDown:  "RightFaceDown",
+	rl.GamepadButtonRightFaceLeft:  "RightFaceLeft",
+	rl.GamepadButtonLeftTrigger1:   "LeftTrigger1",
+	rl.GamepadButtonLeftTrigger2:   "LeftTrigger2",
+	rl.GamepadButtonRightTrigger1:  "RightTrigger1",
+	rl.GamepadButtonRightTrigger2:  "RightTrigger2",
+	rl.GamepadButtonMiddleLeft:     "MiddleLeft",
+	rl.GamepadButtonMiddle:         "Middle",
+	rl.GamepadButtonMiddleRight:    "MiddleRight",
+	rl.GamepadButtonLeftThumb:      "LeftThumb",
+	rl.GamepadButtonRightThumb:     "RightThumb",
+}
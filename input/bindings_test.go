@@ -0,0 +1,85 @@
+package input
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestParseBindings(t *testing.T) {
+	bindings, err := ParseBindings(strings.NewReader(`
+# comments and blank lines are skipped
+[keyboard]
+jump = Space, Up
+jump = W, space
+dash = 
+
+[gamepad]
+jump = RightFaceDown
+jump = RightFaceDown
+`))
+	if err != nil {
+		t.Fatalf("expected the bindings to parse, got %s", err.Error())
+	}
+
+	// every line adds to the first one, keys repeated across lines are bound once
+	if expected := []int32{rl.KeySpace, rl.KeyUp, rl.KeyW}; !slices.Equal(bindings[Jump].Keys, expected) {
+		t.Errorf("expected jump to be bound to %v, got %v", expected, bindings[Jump].Keys)
+	}
+
+	if expected := []int32{rl.GamepadButtonRightFaceDown}; !slices.Equal(bindings[Jump].GamepadButtons, expected) {
+		t.Errorf("expected jump to be bound to button %v, got %v", expected, bindings[Jump].GamepadButtons)
+	}
+
+	if len(bindings[Dash].Keys) != 0 || len(bindings[Dash].GamepadButtons) == 0 {
+		t.Errorf("expected dash to lose its keys but keep its buttons, got %v", bindings[Dash])
+	}
+
+	if !slices.Equal(bindings[MoveLeft].Keys, DefaultBindings()[MoveLeft].Keys) {
+		t.Errorf("expected actions left out to keep their defaults, got %v", bindings[MoveLeft].Keys)
+	}
+}
+
+func TestParseBindingsErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		controls string
+		expected string
+	}{
+		{"unknown action", "[keyboard]\nfly = Space", `line 2: unknown action "fly"`},
+		{"unknown key", "[keyboard]\njump = Space, Hyper", `line 2: unknown keyboard input "Hyper"`},
+		{"key in the gamepad section", "[gamepad]\njump = Space", `line 2: unknown gamepad input "Space"`},
+		{"unknown section", "[mouse]\njump = Left", `line 1: unknown section "mouse"`},
+		{"outside of a section", "jump = Space", "line 1: binding outside of a section"},
+		{"missing equals", "[keyboard]\njump Space", `line 2: expected "action = keys"`},
+	} {
+		_, err := ParseBindings(strings.NewReader(testCase.controls))
+
+		if err == nil || err.Error() != testCase.expected {
+			t.Errorf("%s: expected the error %q, got %v", testCase.name, testCase.expected, err)
+		}
+	}
+}
+
+func TestBindingsRoundTrip(t *testing.T) {
+	bindings := DefaultBindings()
+	bindings[Jump].Keys = []int32{rl.KeyUp, rl.KeyK}
+
+	var written strings.Builder
+	if err := bindings.Write(&written); err != nil {
+		t.Fatalf("error writing bindings: %s", err.Error())
+	}
+
+	read, err := ParseBindings(strings.NewReader(written.String()))
+	if err != nil {
+		t.Fatalf("error reading the written bindings: %s", err.Error())
+	}
+
+	for _, action := range Actions() {
+		if !slices.Equal(read[action].Keys, bindings[action].Keys) || !slices.Equal(read[action].GamepadButtons, bindings[action].GamepadButtons) {
+			t.Errorf("expected %s to be bound to %v, got %v", action, bindings[action], read[action])
+		}
+	}
+}
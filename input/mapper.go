@@ -0,0 +1,108 @@
+package input
+
+import (
+	"slices"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Mapper turns the keyboard and gamepad state into actions, it is the only place that
+// polls raylib for input.
+type Mapper struct {
+	Bindings      Bindings
+	ActiveGamepad int32
+}
+
+func NewMapper(bindings Bindings) *Mapper {
+	return &Mapper{
+		Bindings: bindings,
+	}
+}
+
+func (m *Mapper) Poll() State {
+	m.DetectActiveGamepad()
+
+	var state State
+	for action, binding := range m.Bindings {
+		var held, pressed, released bool
+
+		for _, key := range binding.Keys {
+			held = held || rl.IsKeyDown(key)
+			pressed = pressed || rl.IsKeyPressed(key)
+			released = released || rl.IsKeyReleased(key)
+		}
+
+		for _, button := range binding.GamepadButtons {
+			held = held || rl.IsGamepadButtonDown(m.ActiveGamepad, button)
+			pressed = pressed || rl.IsGamepadButtonPressed(m.ActiveGamepad, button)
+			released = released || rl.IsGamepadButtonReleased(m.ActiveGamepad, button)
+		}
+
+		state.Set(action, held, pressed, released)
+	}
+
+	return state
+}
+
+func (m *Mapper) DetectActiveGamepad() {
+	for i := range int32(4) {
+		if rl.IsGamepadAvailable(i) {
+			m.ActiveGamepad = i
+			return
+		}
+	}
+}
+
+// RebindNextPress binds the action to the key or gamepad button pressed this frame, for
+// menus waiting on the player to choose one. Only keys with a name are taken, so the
+// bindings can still be saved. It returns whether the action got bound.
+func (m *Mapper) RebindNextPress(action Action) bool {
+	for key := range keyNames {
+		if rl.IsKeyPressed(key) {
+			m.Rebind(action, key)
+			return true
+		}
+	}
+
+	m.DetectActiveGamepad()
+	for button := range gamepadButtonNames {
+		if rl.IsGamepadButtonPressed(m.ActiveGamepad, button) {
+			m.RebindGamepad(action, button)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rebind replaces every key bound to the action, gamepad buttons are left untouched. The
+// key is taken away from any other action using it, a key only ever does one thing.
+func (m *Mapper) Rebind(action Action, key int32) {
+	for _, binding := range m.Bindings {
+		binding.Keys = slices.DeleteFunc(binding.Keys, func(bound int32) bool {
+			return bound == key
+		})
+	}
+
+	m.binding(action).Keys = []int32{key}
+}
+
+// RebindGamepad replaces every gamepad button bound to the action, keys are left untouched.
+// Like keys, the button is taken away from any other action using it.
+func (m *Mapper) RebindGamepad(action Action, button int32) {
+	for _, binding := range m.Bindings {
+		binding.GamepadButtons = slices.DeleteFunc(binding.GamepadButtons, func(bound int32) bool {
+			return bound == button
+		})
+	}
+
+	m.binding(action).GamepadButtons = []int32{button}
+}
+
+func (m *Mapper) binding(action Action) *Binding {
+	if _, ok := m.Bindings[action]; !ok {
+		m.Bindings[action] = &Binding{}
+	}
+
+	return m.Bindings[action]
+}
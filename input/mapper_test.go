@@ -0,0 +1,49 @@
+package input
+
+import (
+	"slices"
+	"testing"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestRebindTakesTheKeyFromOtherActions(t *testing.T) {
+	mapper := NewMapper(DefaultBindings())
+	mapper.Bindings[Interact].Keys = []int32{rl.KeyE, rl.KeyA}
+
+	mapper.Rebind(Jump, rl.KeyA)
+
+	if !slices.Equal(mapper.Bindings[Jump].Keys, []int32{rl.KeyA}) {
+		t.Fatalf("expected jump to be bound to A, got %v", mapper.Bindings[Jump].Keys)
+	}
+
+	if len(mapper.Bindings[MoveLeft].Keys) != 0 {
+		t.Fatalf("expected move left to lose A, got %v", mapper.Bindings[MoveLeft].Keys)
+	}
+
+	if !slices.Equal(mapper.Bindings[Interact].Keys, []int32{rl.KeyE}) {
+		t.Fatalf("expected interact to keep its other keys, got %v", mapper.Bindings[Interact].Keys)
+	}
+
+	if !slices.Equal(mapper.Bindings[MoveLeft].GamepadButtons, DefaultBindings()[MoveLeft].GamepadButtons) {
+		t.Fatalf("expected rebinding a key to leave the buttons alone, got %v", mapper.Bindings[MoveLeft].GamepadButtons)
+	}
+}
+
+func TestRebindGamepadTakesTheButtonFromOtherActions(t *testing.T) {
+	mapper := NewMapper(DefaultBindings())
+
+	mapper.RebindGamepad(Dash, rl.GamepadButtonRightFaceDown)
+
+	if !slices.Equal(mapper.Bindings[Dash].GamepadButtons, []int32{rl.GamepadButtonRightFaceDown}) {
+		t.Fatalf("expected dash to be bound to the button, got %v", mapper.Bindings[Dash].GamepadButtons)
+	}
+
+	if len(mapper.Bindings[Jump].GamepadButtons) != 0 {
+		t.Fatalf("expected jump to lose the button, got %v", mapper.Bindings[Jump].GamepadButtons)
+	}
+
+	if !slices.Equal(mapper.Bindings[Jump].Keys, DefaultBindings()[Jump].Keys) {
+		t.Fatalf("expected rebinding a button to leave the keys alone, got %v", mapper.Bindings[Jump].Keys)
+	}
+}
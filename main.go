@@ -2,16 +2,20 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"game3/game"
+	"game3/input"
 	"game3/ui"
+	"math"
+	"os"
 	"time"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
-	VIRTUAL_WINDOW_WIDTH  int32  = 320
-	VIRTUAL_WINDOW_HEIGHT int32  = 180
+	VIRTUAL_WINDOW_WIDTH  int32  = int32(game.VIEWPORT_WIDTH)
+	VIRTUAL_WINDOW_HEIGHT int32  = int32(game.VIEWPORT_HEIGHT)
 	GAME_TITLE            string = "game3"
 	FLOOR_TILE_SIZE       int32  = 16
 	UI_HEIGHT             int32  = 5
@@ -27,6 +31,11 @@ var (
 )
 
 func main() {
+	// subcommands run before any window is opened
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate(os.Args[2:]))
+	}
+
 	slowMotionScale := 1
 	rl.InitWindow(VIRTUAL_WINDOW_WIDTH*3, VIRTUAL_WINDOW_HEIGHT*3, GAME_TITLE)
 	defer rl.CloseWindow()
@@ -43,38 +52,93 @@ func main() {
 
 	debugMode := flag.Bool("debug", false, "init the game in debug mode")
 	raycastedCCDMode := flag.Bool("raycasted", false, "use raycasted ccd")
+	controlsPath := flag.String("controls", input.DEFAULT_CONTROLS_PATH, "path to the controls file")
+	recordPath := flag.String("record", "", "record the run into a replay file")
+	replayPath := flag.String("replay", "", "play back a replay file")
+	profilePath := flag.String("profile", game.DEFAULT_PROFILE_PATH, "path to the movement profile file")
+	profilePreset := flag.String("preset", game.DEFAULT_PROFILE_PRESET, "movement preset to use from the profile file")
+	transitionName := flag.String("transition", game.DefaultTransitionSettings().Type.String(), "how rooms change: cut, slide or fade")
+	transitionTime := flag.Float64("transition-time", float64(game.ROOM_TRANSITION_FRAMES)/float64(game.SIMULATION_FPS), "seconds a room transition lasts")
 	flag.Parse()
 
-	instance := game.InitGame(*debugMode, *raycastedCCDMode)
+	bindings, loadBindingsErr := input.LoadBindings(*controlsPath)
+	if loadBindingsErr != nil {
+		panic(fmt.Sprintf("error loading controls: %s", loadBindingsErr.Error()))
+	}
+	mapper := input.NewMapper(bindings)
+	controlsMenu := ui.NewControlsMenu(mapper, *controlsPath)
+
+	profile, loadProfileErr := game.LoadMovementProfile(*profilePath, *profilePreset)
+	if loadProfileErr != nil {
+		panic(fmt.Sprintf("error loading movement profile: %s", loadProfileErr.Error()))
+	}
+
+	transitionType, parseTransitionErr := game.ParseTransitionType(*transitionName)
+	if parseTransitionErr != nil {
+		panic(fmt.Sprintf("error loading room transition: %s", parseTransitionErr.Error()))
+	}
+
+	seed := time.Now().UnixNano()
+
+	var replay *game.Replay
+	if *replayPath != "" {
+		var loadReplayErr error
+		replay, loadReplayErr = game.LoadReplay(*replayPath)
+		if loadReplayErr != nil {
+			panic(fmt.Sprintf("error loading replay: %s", loadReplayErr.Error()))
+		}
+
+		seed = replay.Seed
+		*raycastedCCDMode = replay.CollisionSystem == game.RayCastedCollision
+	}
+
+	instance := game.InitGame(*debugMode, *raycastedCCDMode, seed)
+	instance.Player.Profile = profile
+	instance.TransitionSettings = game.TransitionSettings{
+		Type:   transitionType,
+		Frames: int32(math.Round(*transitionTime * float64(game.SIMULATION_FPS))),
+	}
+
+	if replay != nil {
+		if startPlaybackErr := instance.StartPlayback(replay); startPlaybackErr != nil {
+			panic(fmt.Sprintf("error starting replay: %s", startPlaybackErr.Error()))
+		}
+	}
 
-	for !rl.WindowShouldClose() {
+	if *recordPath != "" {
+		instance.StartRecording()
+	}
+
+	var profileWatcher *game.ProfileWatcher
+	if *debugMode {
+		profileWatcher = game.NewProfileWatcher(*profilePath)
+	}
+
+	for frame := 0; !rl.WindowShouldClose(); frame++ {
 		updateScreenScale()
 
-		delta := rl.GetFrameTime()
+		if profileWatcher != nil && frame%int(TARGET_FPS) == 0 && profileWatcher.Changed() {
+			reloadMovementProfile(instance, *profilePath, *profilePreset)
+		}
 
-		rl.BeginTextureMode(virtualScreen)
-		{
-			rl.ClearBackground(rl.Black)
+		frameTime := min(rl.GetFrameTime(), game.MAX_FRAME_TIME)
 
-			if rl.IsKeyPressed(rl.KeyEscape) && instance.State == game.Playing {
-				instance.State = game.Paused
-			}
+		actions := mapper.Poll()
+		instance.ProcessInput(actions)
 
-			if rl.IsKeyPressed(rl.KeyEscape) && instance.State == game.Paused {
-				instance.State = game.Playing
-			}
+		if instance.State == game.Playing {
+			instance.Advance(frameTime/float32(slowMotionScale), actions)
+		}
 
-			if rl.IsKeyPressed(rl.KeyR) || rl.IsGamepadButtonPressed(0, rl.GamepadButtonMiddleLeft) {
-				instance.Reset()
-			}
+		rl.BeginTextureMode(virtualScreen)
+		{
+			rl.ClearBackground(rl.Black)
 
 			if instance.State == game.MainMenu {
-				ui.ShowMainMenu(instance)
-			}
-
-			if instance.State == game.Playing {
-				instance.Tick(delta / float32(slowMotionScale))
-				time.Sleep(time.Millisecond * time.Duration(slowMotionScale))
+				ui.ShowMainMenu(instance, controlsMenu)
+			} else {
+				instance.Render(instance.Accumulator / game.FIXED_DELTA)
+				ui.ShowHUD(instance)
 			}
 		}
 		rl.EndTextureMode()
@@ -85,6 +149,30 @@ func main() {
 		}
 		rl.EndDrawing()
 	}
+
+	if instance.Recording != nil {
+		if saveReplayErr := instance.Recording.Save(*recordPath); saveReplayErr != nil {
+			rl.TraceLog(rl.LogError, "error saving replay: %s", saveReplayErr.Error())
+		}
+	}
+}
+
+// reloadMovementProfile keeps the current profile when the file is broken, so a typo while
+// tweaking does not take the game down. Recordings and replays stick to their own profile.
+func reloadMovementProfile(instance *game.Game, path string, preset string) {
+	if instance.Recording != nil || instance.Playback != nil {
+		rl.TraceLog(rl.LogWarning, "movement profile changed, not reloading it while recording or replaying")
+		return
+	}
+
+	profile, err := game.LoadMovementProfile(path, preset)
+	if err != nil {
+		rl.TraceLog(rl.LogWarning, "error reloading movement profile: %s", err.Error())
+		return
+	}
+
+	instance.Player.Profile = profile
+	rl.TraceLog(rl.LogInfo, "movement profile %s reloaded", preset)
 }
 
 func updateScreenScale() {
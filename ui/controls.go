@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fmt"
+	"game3/input"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const CONTROLS_ROW_HEIGHT float32 = 12
+
+// ControlsMenu lists every action with what it is bound to. Clicking one waits for the
+// next key or gamepad button, which replaces the old one and is saved right away. Any other
+// action bound to it loses it.
+type ControlsMenu struct {
+	Mapper    *input.Mapper
+	Path      string
+	Open      bool
+	IsWaiting bool
+	Rebinding input.Action
+}
+
+func NewControlsMenu(mapper *input.Mapper, path string) *ControlsMenu {
+	return &ControlsMenu{
+		Mapper: mapper,
+		Path:   path,
+	}
+}
+
+func (c *ControlsMenu) Show() {
+	if c.IsWaiting && c.Mapper.RebindNextPress(c.Rebinding) {
+		c.IsWaiting = false
+
+		if err := c.Mapper.Bindings.Save(c.Path); err != nil {
+			rl.TraceLog(rl.LogError, "error saving controls: %s", err.Error())
+		}
+	}
+
+	menu := NewUiElement(NewUiElementInput{
+		Width:           float32(VIRTUAL_WINDOW_WIDTH),
+		Height:          float32(VIRTUAL_WINDOW_HEIGHT),
+		BackgroundColor: greenishBlack,
+		BorderColor:     regularGreen,
+		BorderWidth:     2,
+		HPosition:       HCentered,
+		VPosition:       VCentered,
+	})
+
+	rows := make([]UiElement, 0, len(input.Actions()))
+	for i, action := range input.Actions() {
+		rows = append(rows, NewUiElement(NewUiElementInput{
+			Width:           220,
+			Height:          CONTROLS_ROW_HEIGHT - 2,
+			BackgroundColor: greenishBlack,
+			BorderColor:     regularGreen,
+			BorderWidth:     1,
+			HPosition:       Top,
+			VPosition:       VCentered,
+			Margin:          UiMargin{Top: 4 + float32(i)*CONTROLS_ROW_HEIGHT},
+			Text:            c.describe(action),
+		}))
+	}
+
+	for i, action := range input.Actions() {
+		row := &rows[i]
+
+		row.AddEventListener("click", func() {
+			c.IsWaiting = true
+			c.Rebinding = action
+		})
+
+		row.AddEventListener("hover", func() {
+			row.SetBackgroundColor(dirtyYellow)
+		})
+
+		menu.AddChild(row)
+	}
+
+	backButton := NewUiElement(NewUiElementInput{
+		Width:           100,
+		Height:          CONTROLS_ROW_HEIGHT,
+		BackgroundColor: greenishBlack,
+		BorderColor:     regularGreen,
+		BorderWidth:     1,
+		HPosition:       Bottom,
+		VPosition:       VCentered,
+		Margin:          UiMargin{Bottom: 4},
+		Text:            "Back",
+	})
+
+	backButton.AddEventListener("click", func() {
+		c.Open = false
+		c.IsWaiting = false
+	})
+
+	backButton.AddEventListener("hover", func() {
+		backButton.SetBackgroundColor(dirtyYellow)
+	})
+
+	menu.AddChild(&backButton)
+
+	menu.Tick()
+}
+
+func (c *ControlsMenu) describe(action input.Action) string {
+	if c.IsWaiting && c.Rebinding == action {
+		return fmt.Sprintf("%s: press a key or button", action)
+	}
+
+	binding, ok := c.Mapper.Bindings[action]
+	if !ok {
+		return fmt.Sprintf("%s: unbound", action)
+	}
+
+	return fmt.Sprintf("%s: %s / %s", action, binding.FormatKeys(), binding.FormatGamepadButtons())
+}
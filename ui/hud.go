@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"game3/game"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const HEALTH_PER_HEART int8 = 2
+
+var heartTilemapPositions = map[int8]rl.Vector2{
+	2: rl.NewVector2(96, 64),
+	1: rl.NewVector2(104, 64),
+	0: rl.NewVector2(112, 64),
+}
+
+// ShowHUD draws a heart for every two points of health, half hearts included.
+func ShowHUD(instance *game.Game) {
+	player := instance.Player
+	tilemap := instance.Renderer.Textures["tilemap"]
+
+	hearts := (player.MaxHealth + HEALTH_PER_HEART - 1) / HEALTH_PER_HEART
+	for i := range hearts {
+		filled := min(max(player.Health-i*HEALTH_PER_HEART, 0), HEALTH_PER_HEART)
+		spritePosition := heartTilemapPositions[filled]
+
+		rec := rl.NewRectangle(spritePosition.X, spritePosition.Y, 8, 8)
+		rl.DrawTextureRec(tilemap, rec, rl.NewVector2(float32(4+int(i)*9), 4), rl.White)
+	}
+}
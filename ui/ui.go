@@ -7,8 +7,8 @@ import (
 )
 
 const (
-	VIRTUAL_WINDOW_WIDTH  int = 320
-	VIRTUAL_WINDOW_HEIGHT int = 180
+	VIRTUAL_WINDOW_WIDTH  int = int(game.VIEWPORT_WIDTH)
+	VIRTUAL_WINDOW_HEIGHT int = int(game.VIEWPORT_HEIGHT)
 )
 
 var greenishBlack rl.Color = rl.NewColor(88, 68, 34, 255)
@@ -16,7 +16,12 @@ var dirtyYellow rl.Color = rl.NewColor(212, 210, 155, 255)
 var regularGreen rl.Color = rl.NewColor(94, 133, 73, 255)
 var regularGreenHover rl.Color = rl.NewColor(100, 200, 100, 255)
 
-func ShowMainMenu(instance *game.Game) {
+func ShowMainMenu(instance *game.Game, controls *ControlsMenu) {
+	if controls.Open {
+		controls.Show()
+		return
+	}
+
 	menu := NewUiElement(NewUiElementInput{
 		Width:           float32(VIRTUAL_WINDOW_WIDTH),
 		Height:          float32(VIRTUAL_WINDOW_HEIGHT),
@@ -72,7 +77,7 @@ func ShowMainMenu(instance *game.Game) {
 	})
 
 	optionsButton.AddEventListener("click", func() {
-		rl.TraceLog(rl.LogInfo, "options button clicked")
+		controls.Open = true
 	})
 
 	optionsButton.AddEventListener("hover", func() {
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"game3/game"
+	"os"
+)
+
+// validate checks an LDtk world, the one embedded in the binary unless a path is given,
+// and returns the exit code.
+func validate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: game3 validate [path to .ldtk file]")
+	}
+	flags.Parse(args)
+
+	var world *game.World
+	var err error
+	source := "embedded world"
+
+	if flags.NArg() > 0 {
+		source = flags.Arg(0)
+		world, err = game.LoadWorldFromFile(source)
+	} else {
+		world, err = game.LoadWorld()
+	}
+
+	if err != nil {
+		// a broken world reports every problem found, joined in a single error
+		problems := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			problems = joined.Unwrap()
+		}
+
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", source, problem.Error())
+		}
+
+		fmt.Fprintf(os.Stderr, "%d problems found\n", len(problems))
+		return 1
+	}
+
+	fmt.Printf("%s: %d levels ok\n", source, len(world.Levels))
+
+	return 0
+}